@@ -0,0 +1,85 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yamlfmt
+
+import (
+	"bytes"
+	"fmt"
+)
+
+var UTF8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// BOMMode controls what happens to a UTF-8 byte order mark when
+// a file is formatted.
+type BOMMode string
+
+const (
+	// BOMModePreserve keeps a byte order mark only on files that
+	// already had one.
+	BOMModePreserve BOMMode = "preserve"
+	// BOMModeStrip removes the byte order mark from every file.
+	BOMModeStrip BOMMode = "strip"
+	// BOMModeAdd writes a byte order mark to every file.
+	BOMModeAdd BOMMode = "add"
+)
+
+type UnsupportedBOMModeError struct {
+	mode BOMMode
+}
+
+func (e UnsupportedBOMModeError) Error() string {
+	return fmt.Sprintf("unsupported bom mode %s, supported modes are preserve, strip, and add", e.mode)
+}
+
+// StripBOM removes a leading UTF-8 byte order mark from content,
+// reporting whether there was one.
+func StripBOM(content []byte) ([]byte, bool) {
+	if bytes.HasPrefix(content, UTF8BOM) {
+		return content[len(UTF8BOM):], true
+	}
+	return content, false
+}
+
+// Validate returns an error if m isn't a supported mode. An empty mode is
+// valid, and behaves like BOMModePreserve.
+func (m BOMMode) Validate() error {
+	switch m {
+	case BOMModePreserve, BOMModeStrip, BOMModeAdd, "":
+		return nil
+	}
+	return UnsupportedBOMModeError{mode: m}
+}
+
+// Apply adds or leaves off the byte order mark on formatted content
+// according to the mode. hadBOM reports whether the original content
+// started with a byte order mark. An empty mode behaves like
+// BOMModePreserve.
+func (m BOMMode) Apply(formatted []byte, hadBOM bool) ([]byte, error) {
+	addBOM := false
+	switch m {
+	case BOMModePreserve, "":
+		addBOM = hadBOM
+	case BOMModeStrip:
+	case BOMModeAdd:
+		addBOM = true
+	default:
+		return nil, UnsupportedBOMModeError{mode: m}
+	}
+	formatted, _ = StripBOM(formatted)
+	if !addBOM {
+		return formatted, nil
+	}
+	return append(bytes.Clone(UTF8BOM), formatted...), nil
+}
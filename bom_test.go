@@ -0,0 +1,64 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yamlfmt_test
+
+import (
+	"testing"
+
+	"github.com/google/yamlfmt"
+	"github.com/google/yamlfmt/internal/assert"
+)
+
+func TestBOMModeApply(t *testing.T) {
+	bom := string(yamlfmt.UTF8BOM)
+	testCases := []struct {
+		name     string
+		mode     yamlfmt.BOMMode
+		hadBOM   bool
+		expected string
+	}{
+		{name: "preserve with bom", mode: yamlfmt.BOMModePreserve, hadBOM: true, expected: bom + "a: 1\n"},
+		{name: "preserve without bom", mode: yamlfmt.BOMModePreserve, expected: "a: 1\n"},
+		{name: "empty mode preserves", mode: "", hadBOM: true, expected: bom + "a: 1\n"},
+		{name: "strip", mode: yamlfmt.BOMModeStrip, hadBOM: true, expected: "a: 1\n"},
+		{name: "add", mode: yamlfmt.BOMModeAdd, expected: bom + "a: 1\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tc.mode.Apply([]byte("a: 1\n"), tc.hadBOM)
+			assert.NilErr(t, err)
+			assert.Equal(t, tc.expected, string(result))
+		})
+	}
+
+	_, err := yamlfmt.BOMMode("sometimes").Apply([]byte("a: 1\n"), false)
+	assert.NotNilErr(t, err)
+}
+
+func TestBOMModeValidate(t *testing.T) {
+	assert.NotNilErr(t, yamlfmt.BOMMode("sometimes").Validate())
+	assert.NilErr(t, yamlfmt.BOMModeStrip.Validate())
+	assert.NilErr(t, yamlfmt.BOMMode("").Validate())
+}
+
+func TestStripBOM(t *testing.T) {
+	content, hadBOM := yamlfmt.StripBOM([]byte(string(yamlfmt.UTF8BOM) + "a: 1\n"))
+	assert.Equal(t, true, hadBOM)
+	assert.Equal(t, "a: 1\n", string(content))
+	content, hadBOM = yamlfmt.StripBOM([]byte("a: 1\n"))
+	assert.Equal(t, false, hadBOM)
+	assert.Equal(t, "a: 1\n", string(content))
+}
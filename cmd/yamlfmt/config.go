@@ -21,27 +21,16 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
-	"strconv"
 	"strings"
 
 	"github.com/google/yamlfmt"
 	"github.com/google/yamlfmt/command"
-	"github.com/google/yamlfmt/engine"
 	"github.com/google/yamlfmt/formatters/kyaml"
 	"github.com/google/yamlfmt/internal/collections"
 	"github.com/google/yamlfmt/internal/logger"
-	"github.com/google/yamlfmt/pkg/yaml"
 	"github.com/mitchellh/mapstructure"
 )
 
-var configFileNames = collections.Set[string]{
-	".yamlfmt":      {},
-	".yamlfmt.yml":  {},
-	".yamlfmt.yaml": {},
-	"yamlfmt.yml":   {},
-	"yamlfmt.yaml":  {},
-}
-
 const configHomeDir string = "yamlfmt"
 
 var (
@@ -74,19 +63,6 @@ func (e *configPathError) Unwrap() error {
 	return e.err
 }
 
-func readConfig(path string) (map[string]any, error) {
-	yamlBytes, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-	var configData map[string]interface{}
-	err = yaml.Unmarshal(yamlBytes, &configData)
-	if err != nil {
-		return nil, err
-	}
-	return configData, nil
-}
-
 func getConfigPath() (string, error) {
 	// First priority: specified in cli flag
 	configPath, err := getConfigPathFromFlag()
@@ -205,7 +181,7 @@ func getConfigPathFromAppDataLocal() (string, error) {
 }
 
 func getConfigPathFromDir(dir string) (string, error) {
-	for filename := range configFileNames {
+	for _, filename := range command.ConfigFileNames {
 		configPath := filepath.Join(dir, filename)
 		if err := validatePath(configPath); err == nil {
 			logger.Debug(logger.DebugCodeConfig, "Found config at %s", configPath)
@@ -239,70 +215,107 @@ func validatePath(path string) error {
 	return nil
 }
 
-func makeCommandConfigFromData(configData map[string]any) (*command.Config, error) {
-	altFormatter, err := detectAlternateFormatterFlag()
+// configFlags maps the flags that set a config key to the key they set.
+var configFlags = map[string]string{
+	"dstar":              "doublestar",
+	"continue_on_error":  "continue_on_error",
+	"gitignore_excludes": "gitignore_excludes",
+	"gitignore_path":     "gitignore_path",
+	"output_format":      "output_format",
+	"match_type":         "match_type",
+	"changed_since":      "changed_since",
+	"changed_lines":      "changed_lines",
+	"symlinks":           "symlinks",
+	"sniff_content":      "sniff_content",
+}
+
+// applyConfigOverrides merges the config keys set by YAMLFMT_<KEY>
+// environment variables over the config file data, and then the keys set
+// by flags over those. Only flags that were passed on the command line
+// are applied, so the precedence is flags, then the environment, then the
+// config file, then the defaults.
+func applyConfigOverrides(configData map[string]any, sources command.ConfigSources) error {
+	envData, envSources, err := command.EnvConfigData(os.Environ())
 	if err != nil {
-		return nil, err
+		return err
 	}
+	command.MergeConfigData(configData, sources, envData, envSources)
+
+	flagData := map[string]any{}
+	flagSources := command.ConfigSources{}
+	flag.Visit(func(f *flag.Flag) {
+		key, ok := configFlags[f.Name]
+		if !ok {
+			return
+		}
+		flagData[key] = f.Value.(flag.Getter).Get()
+		flagSources[key] = "flag -" + f.Name
+	})
+	command.MergeConfigData(configData, sources, flagData, flagSources)
 
-	config := command.Config{FormatterConfig: command.NewFormatterConfig()}
+	altFormatter, err := detectAlternateFormatterFlag()
+	if err != nil {
+		return err
+	}
 	if altFormatter != "" {
 		// If an alternate formatter was specified via CLI flag, override
 		// the formatter from the configuration.
-		config.FormatterConfig.Type = altFormatter
-	} else {
-		err := mapstructure.Decode(configData, &config)
-		if err != nil {
-			return nil, err
-		}
+		delete(configData, "formatter")
+		sources.Remove("formatter")
+		command.MergeConfigData(configData, sources,
+			map[string]any{"formatter": map[string]any{"type": altFormatter}},
+			command.ConfigSources{"formatter.type": "flag -" + altFormatter})
 	}
 
 	// Parse overrides for formatter configuration
 	if len(flagFormatter) > 0 {
-		overrides, err := parseFormatterConfigFlag(flagFormatter)
+		formatterData, formatterSources, err := parseFormatterConfigFlag(flagFormatter)
 		if err != nil {
-			return nil, err
-		}
-		for k, v := range overrides {
-			if k == "type" {
-				config.FormatterConfig.Type = v.(string)
-			}
-			config.FormatterConfig.FormatterSettings[k] = v
+			return err
 		}
+		command.MergeConfigData(configData, sources, formatterData, formatterSources)
+	}
+
+	setData, setSources, err := command.SetConfigData(flagSet, "flag -set")
+	if err != nil {
+		return err
 	}
+	command.MergeConfigData(configData, sources, setData, setSources)
+	return nil
+}
+
+func makeCommandConfigFromData(configData map[string]any) (*command.Config, error) {
+	config := command.Config{FormatterConfig: command.NewFormatterConfig()}
+	err := mapstructure.Decode(configData, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	defaults := command.DefaultConfig()
 
 	// Default to OS line endings
 	if config.LineEnding == "" {
-		config.LineEnding = yamlfmt.LineBreakStyleLF
-		if runtime.GOOS == "windows" {
-			config.LineEnding = yamlfmt.LineBreakStyleCRLF
-		}
+		config.LineEnding = defaults.LineEnding
+	}
+
+	if config.BOM == "" {
+		config.BOM = defaults.BOM
 	}
 
 	// Default to yaml and yml extensions
 	if len(config.Extensions) == 0 {
-		config.Extensions = []string{"yaml", "yml"}
+		config.Extensions = defaults.Extensions
 	}
 
-	// Apply the general rule that the config takes precedence over
-	// the command line flags.
-	if !config.Doublestar {
-		config.Doublestar = *flagDoublestar
-	}
-	if !config.ContinueOnError {
-		config.ContinueOnError = *flagContinueOnError
-	}
-	if !config.GitignoreExcludes {
-		config.GitignoreExcludes = *flagGitignoreExcludes
-	}
-	config.GitignorePath = pickFirst(config.GitignorePath, *flagGitignorePath)
-	config.OutputFormat = pickFirst(config.OutputFormat, getOutputFormatFromFlag(), engine.EngineOutputDefault)
+	config.GitignorePath = pickFirst(config.GitignorePath, defaults.GitignorePath)
+	config.OutputFormat = pickFirst(config.OutputFormat, defaults.OutputFormat)
+	config.Symlinks = pickFirst(config.Symlinks, defaults.Symlinks)
 
-	defaultMatchType := yamlfmt.MatchTypeStandard
+	defaultMatchType := defaults.MatchType
 	if config.Doublestar {
 		defaultMatchType = yamlfmt.MatchTypeDoublestar
 	}
-	config.MatchType = pickFirst(config.MatchType, yamlfmt.MatchType(*flagMatchType), defaultMatchType)
+	config.MatchType = pickFirst(config.MatchType, defaultMatchType)
 
 	// Overwrite config if includes are provided through args
 	if len(flag.Args()) > 0 {
@@ -312,6 +325,7 @@ func makeCommandConfigFromData(configData map[string]any) (*command.Config, erro
 	// Append any additional data from array flags
 	config.Exclude = append(config.Exclude, flagExclude...)
 	config.Extensions = append(config.Extensions, flagExtensions...)
+	config.Filenames = append(config.Filenames, flagFilenames...)
 
 	return &config, nil
 }
@@ -327,41 +341,29 @@ func pickFirst[T ~string](ss ...T) T {
 	return ""
 }
 
-func parseFormatterConfigFlag(flagValues []string) (map[string]any, error) {
-	formatterValues := map[string]any{}
+// parseFormatterConfigFlag parses the fieldname=value settings of the
+// -formatter flag. Values are parsed as yaml, so lists, numbers and
+// booleans get the same types they would have in a config file, and
+// dotted field names set nested settings. Only the first = separates the
+// field name from the value.
+func parseFormatterConfigFlag(flagValues []string) (map[string]any, command.ConfigSources, error) {
 	flagErrors := collections.Errors{}
-
-	// Expected format: fieldname=value
+	settings := []string{}
 	for _, configField := range flagValues {
-		if strings.Count(configField, "=") != 1 {
+		key, _, ok := strings.Cut(configField, "=")
+		if !ok || key == "" {
 			flagErrors = append(
 				flagErrors,
 				fmt.Errorf("badly formatted config field: %s", configField),
 			)
 			continue
 		}
-
-		kv := strings.Split(configField, "=")
-
-		// Try to parse as integer
-		vInt, err := strconv.ParseInt(kv[1], 10, 64)
-		if err == nil {
-			formatterValues[kv[0]] = vInt
-			continue
-		}
-
-		// Try to parse as boolean
-		vBool, err := strconv.ParseBool(kv[1])
-		if err == nil {
-			formatterValues[kv[0]] = vBool
-			continue
-		}
-
-		// Fall through to parsing as string
-		formatterValues[kv[0]] = kv[1]
+		settings = append(settings, "formatter."+configField)
 	}
-
-	return formatterValues, flagErrors.Combine()
+	if err := flagErrors.Combine(); err != nil {
+		return nil, nil, err
+	}
+	return command.SetConfigData(settings, "flag -formatter")
 }
 
 func detectAlternateFormatterFlag() (string, error) {
@@ -21,7 +21,6 @@ import (
 	"strings"
 
 	"github.com/google/yamlfmt"
-	"github.com/google/yamlfmt/engine"
 )
 
 var (
@@ -35,6 +34,10 @@ operation without performing it.`)
 	flagGlobalConf        *bool   = flag.Bool("global_conf", false, fmt.Sprintf("Use global yamlfmt config from %s", globalConfFlagVar()))
 	flagDisableGlobalConf *bool   = flag.Bool("no_global_conf", false, fmt.Sprintf("Disabled usage of global yamlfmt config from %s", globalConfFlagVar()))
 	flagPrintConf         *bool   = flag.Bool("print_conf", false, "Print config")
+	flagPrintSchema       *bool   = flag.Bool("print_schema", false, "Print the JSON Schema for the config file")
+	flagExplain           *string = flag.String("explain", "", "Explain which config and rules apply to this path")
+	flagInit              *bool   = flag.Bool("init", false, "Write a .yamlfmt config file with the default settings to the working directory")
+	flagInitInfer         *bool   = flag.Bool("init_infer", false, "With -init, infer the indent, line endings and array style from the existing yaml files")
 	flagDoublestar        *bool   = flag.Bool("dstar", false, "Use doublestar globs for include and exclude")
 	flagQuiet             *bool   = flag.Bool("quiet", false, "Print minimal output to stdout")
 	flagQuietShort        *bool   = flag.Bool("q", false, "Print minimal output to stdout")
@@ -44,19 +47,28 @@ operation without performing it.`)
 	flagGitignoreExcludes *bool   = flag.Bool("gitignore_excludes", false, "Use a gitignore file for excludes")
 	flagGitignorePath     *string = flag.String("gitignore_path", ".gitignore", "Path to gitignore file to use")
 	flagOutputFormat      *string = flag.String("output_format", "default", "The engine output format")
-	flagMatchType         *string = flag.String("match_type", "", "The file discovery method to use. Valid values: standard, doublestar, gitignore")
+	flagMatchType         *string = flag.String("match_type", "", "The file discovery method to use. Valid values: standard, doublestar, gitignore, git")
+	flagChangedSince      *string = flag.String("changed_since", "", "Only format files that were added or modified since this git revision")
+	flagChangedLines      *bool   = flag.Bool("changed_lines", false, "With -changed_since, only format the top level nodes that contain changed lines")
+	flagLines             *string = flag.String("lines", "", "Only format the top level nodes that overlap these line ranges, in the form start:end[,start:end...]")
+	flagSymlinks          *string = flag.String("symlinks", "", "How to treat symlinks when collecting paths. Valid values: skip, follow, follow_within_root")
+	flagSniffContent      *bool   = flag.Bool("sniff_content", false, "Also collect files without an extension whose content looks like YAML")
 	flagKyaml             *bool   = flag.Bool("kyaml", false, "Flag to switch to kyaml formatting. If used, all formatter configuration from detected from configuration file is overridden.")
 	flagExclude                   = arrayFlag{}
-	flagFormatter                 = arrayFlag{}
+	flagFormatter                 = settingsFlag{}
 	flagExtensions                = arrayFlag{}
+	flagFilenames                 = arrayFlag{}
 	flagDebug                     = arrayFlag{}
+	flagSet                       = repeatedFlag{}
 )
 
 func bindArrayFlags() {
 	flag.Var(&flagExclude, "exclude", "Paths to exclude in the chosen format (standard or doublestar)")
 	flag.Var(&flagFormatter, "formatter", "Config value overrides to pass to the formatter")
 	flag.Var(&flagExtensions, "extensions", "File extensions to use for standard path collection")
+	flag.Var(&flagFilenames, "filenames", "File name patterns to collect whatever their extension in standard and git path collection")
 	flag.Var(&flagDebug, "debug", "Debug codes to activate for debug logging")
+	flag.Var(&flagSet, "set", "Set a config key, in the form key=value. Can be repeated.")
 }
 
 type arrayFlag []string
@@ -75,6 +87,55 @@ func (a *arrayFlag) Set(value string) error {
 	return nil
 }
 
+// repeatedFlag collects the value of each use of a flag. Unlike
+// arrayFlag, values aren't split on commas.
+type repeatedFlag []string
+
+// Implements flag.Value
+func (r *repeatedFlag) String() string {
+	return strings.Join(*r, " ")
+}
+
+func (r *repeatedFlag) Set(value string) error {
+	*r = append(*r, value)
+	return nil
+}
+
+// settingsFlag is like arrayFlag for key=value settings, but only splits
+// on commas that aren't inside brackets, braces or quotes, so that values
+// can use yaml flow syntax like `key=[a,b]`.
+type settingsFlag []string
+
+// Implements flag.Value
+func (s *settingsFlag) String() string {
+	return strings.Join(*s, " ")
+}
+
+func (s *settingsFlag) Set(value string) error {
+	depth := 0
+	var quote rune
+	start := 0
+	for i, r := range value {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '[' || r == '{':
+			depth++
+		case r == ']' || r == '}':
+			depth--
+		case r == ',' && depth == 0:
+			*s = append(*s, value[start:i])
+			start = i + 1
+		}
+	}
+	*s = append(*s, value[start:])
+	return nil
+}
+
 func configureHelp() {
 	flag.Usage = func() {
 		fmt.Println(`yamlfmt is a simple command line tool for formatting yaml files.
@@ -106,13 +167,12 @@ func getOperationFromFlag() yamlfmt.Operation {
 	if *flagPrintConf {
 		return yamlfmt.OperationPrintConfig
 	}
+	if *flagExplain != "" {
+		return yamlfmt.OperationExplain
+	}
 	return yamlfmt.OperationFormat
 }
 
-func getOutputFormatFromFlag() engine.EngineOutputFormat {
-	return engine.EngineOutputFormat(*flagOutputFormat)
-}
-
 func isStdinArg() bool {
 	if len(flag.Args()) != 1 {
 		return false
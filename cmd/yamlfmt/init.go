@@ -0,0 +1,290 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/yamlfmt"
+	"github.com/google/yamlfmt/command"
+	"github.com/google/yamlfmt/formatters/basic"
+	"github.com/google/yamlfmt/internal/jsonschema"
+	"github.com/google/yamlfmt/internal/logger"
+	"github.com/google/yamlfmt/pkg/yaml"
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	initConfigName = ".yamlfmt"
+	// The most files that are read when inferring settings.
+	initInferMaxFiles = 500
+	initCommentWidth  = 78
+)
+
+// runInit writes a config file with the default settings to the working
+// directory, with a comment describing each one. If infer is set, the
+// indent, line endings and array style are taken from the yaml files
+// already in the tree.
+func runInit(infer bool) error {
+	if _, err := os.Stat(initConfigName); err == nil {
+		return fmt.Errorf("%s already exists", initConfigName)
+	}
+
+	config := command.DefaultConfig()
+	// The default line ending depends on the platform yamlfmt runs on, so
+	// it is left out of a config file that is shared by everyone working
+	// on the tree, unless it was inferred from the files in it.
+	config.LineEnding = ""
+	formatterConfig := basic.DefaultConfig()
+	inferredKeys := []string{}
+	if infer {
+		inferred, err := inferSettings(".", config.Extensions)
+		if err != nil {
+			return err
+		}
+		if inferred.files > 0 {
+			config.LineEnding = inferred.lineEnding
+			formatterConfig.Indent = inferred.indent
+			formatterConfig.IndentlessArrays = inferred.indentlessArrays
+			inferredKeys = append(inferredKeys, "indent", "indentless_arrays")
+		}
+		fmt.Printf("inferred settings from %d files\n", inferred.files)
+	}
+
+	content, err := initConfigContent(config, formatterConfig, inferredKeys)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(initConfigName, content, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %s\n", initConfigName)
+	return nil
+}
+
+// initRunKeys are the config keys that only make sense for a single run,
+// so they don't belong in a config file that is committed to the tree.
+var initRunKeys = []string{"changed_since", "changed_lines"}
+
+// initConfigContent renders the config file that -init writes. Settings
+// that are empty, false or zero are left out, since they are the same as
+// not setting them, except for the formatter settings in inferredKeys.
+func initConfigContent(config *command.Config, formatterConfig *basic.Config, inferredKeys []string) ([]byte, error) {
+	commandConfig := map[string]any{}
+	if err := mapstructure.Decode(config, &commandConfig); err != nil {
+		return nil, err
+	}
+	delete(commandConfig, "formatter")
+	for _, key := range initRunKeys {
+		delete(commandConfig, key)
+	}
+	deleteEmptyValues(commandConfig, nil)
+	formatter := &basic.BasicFormatter{Config: formatterConfig}
+	formatterConfigMap, err := formatter.ConfigMap()
+	if err != nil {
+		return nil, err
+	}
+	// The top level line_ending takes precedence, so there is no use
+	// in setting it twice.
+	delete(formatterConfigMap, "line_ending")
+	deleteEmptyValues(formatterConfigMap, inferredKeys)
+
+	var doc yaml.Node
+	if err := doc.Encode(commandConfig); err != nil {
+		return nil, err
+	}
+	var formatterNode yaml.Node
+	if err := formatterNode.Encode(formatterConfigMap); err != nil {
+		return nil, err
+	}
+	moveKeyToFront(&formatterNode, "type")
+	commentKeys(&doc, "")
+	commentKeys(&formatterNode, "formatter.")
+	formatterKey := &yaml.Node{Kind: yaml.ScalarNode, Value: "formatter"}
+	formatterKey.HeadComment = wrapComment(jsonschema.Description("formatter"))
+	doc.Content = append(doc.Content, formatterKey, &formatterNode)
+
+	var buf bytes.Buffer
+	buf.WriteString("# yamlfmt config file. For details, see\n# https://github.com/google/yamlfmt/blob/main/docs/config-file.md\n\n")
+	e := yaml.NewEncoder(&buf)
+	e.SetIndent(2)
+	if err := e.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := e.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// deleteEmptyValues deletes the keys of m that have a zero value or an
+// empty list, other than the ones in keep.
+func deleteEmptyValues(m map[string]any, keep []string) {
+	for key, value := range m {
+		if slices.Contains(keep, key) {
+			continue
+		}
+		v := reflect.ValueOf(value)
+		if !v.IsValid() || v.IsZero() || ((v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0) {
+			delete(m, key)
+		}
+	}
+}
+
+func commentKeys(mapping *yaml.Node, prefix string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key := mapping.Content[i]
+		key.HeadComment = wrapComment(jsonschema.Description(prefix + key.Value))
+	}
+}
+
+func moveKeyToFront(mapping *yaml.Node, name string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == name {
+			pair := slices.Clone(mapping.Content[i : i+2])
+			mapping.Content = slices.Delete(mapping.Content, i, i+2)
+			mapping.Content = slices.Insert(mapping.Content, 0, pair...)
+			return
+		}
+	}
+}
+
+// wrapComment splits s into comment lines of at most initCommentWidth
+// characters.
+func wrapComment(s string) string {
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Fields(s) {
+		if line != "" && len(line)+1+len(word) > initCommentWidth {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+type inferredSettings struct {
+	files  int
+	indent int
+	// Empty if none of the files have line breaks.
+	lineEnding       yamlfmt.LineBreakStyle
+	indentlessArrays bool
+}
+
+var (
+	keyLine      = regexp.MustCompile(`^( *)[^\s#-][^#]*:\s*(#.*)?$`)
+	sequenceLine = regexp.MustCompile(`^( *)- `)
+)
+
+// inferSettings reads the yaml files below root and picks the most
+// common indent, line ending and array style among them.
+func inferSettings(root string, extensions []string) (inferredSettings, error) {
+	indents := map[int]int{}
+	lineEndings := map[yamlfmt.LineBreakStyle]int{}
+	indentlessArrays, indentedArrays := 0, 0
+	files := 0
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !slices.Contains(extensions, strings.TrimPrefix(filepath.Ext(path), ".")) {
+			return nil
+		}
+		if files >= initInferMaxFiles {
+			return filepath.SkipAll
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		logger.Debug(logger.DebugCodeConfig, "inferring settings from %s", path)
+		files++
+		if bytes.Contains(content, []byte("\n")) {
+			style, _ := yamlfmt.DetectLineBreakStyle(content)
+			lineEndings[style]++
+		}
+
+		prevKeyIndent := -1
+		for _, line := range strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n") {
+			if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+				continue
+			}
+			if prevKeyIndent >= 0 {
+				indent := len(line) - len(strings.TrimLeft(line, " "))
+				if m := sequenceLine.FindStringSubmatch(line); m != nil {
+					if len(m[1]) == prevKeyIndent {
+						indentlessArrays++
+					} else if len(m[1]) > prevKeyIndent {
+						indentedArrays++
+						indents[len(m[1])-prevKeyIndent]++
+					}
+				} else if indent > prevKeyIndent {
+					indents[indent-prevKeyIndent]++
+				}
+			}
+			prevKeyIndent = -1
+			if m := keyLine.FindStringSubmatch(line); m != nil {
+				prevKeyIndent = len(m[1])
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return inferredSettings{}, err
+	}
+
+	inferred := inferredSettings{
+		files:            files,
+		indent:           basic.DefaultConfig().Indent,
+		indentlessArrays: indentlessArrays > indentedArrays,
+	}
+	// The line ending is only inferred from files that have line breaks.
+	switch {
+	case lineEndings[yamlfmt.LineBreakStyleCRLF] > lineEndings[yamlfmt.LineBreakStyleLF]:
+		inferred.lineEnding = yamlfmt.LineBreakStyleCRLF
+	case lineEndings[yamlfmt.LineBreakStyleLF] > 0:
+		inferred.lineEnding = yamlfmt.LineBreakStyleLF
+	}
+	mostCommon := 0
+	for indent, count := range indents {
+		if count > mostCommon || (count == mostCommon && indent < inferred.indent) {
+			inferred.indent = indent
+			mostCommon = count
+		}
+	}
+	return inferred, nil
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/google/yamlfmt/command"
 	"github.com/google/yamlfmt/formatters/basic"
 	"github.com/google/yamlfmt/formatters/kyaml"
+	"github.com/google/yamlfmt/internal/jsonschema"
 	"github.com/google/yamlfmt/internal/logger"
 )
 
@@ -55,24 +56,46 @@ func run() error {
 		return nil
 	}
 
+	if *flagPrintSchema {
+		schema, err := jsonschema.Generate()
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(schema))
+		return nil
+	}
+
 	for _, code := range flagDebug {
 		logger.ActivateDebugCode(code)
 	}
 
+	if *flagInit {
+		return runInit(*flagInitInfer)
+	}
+
 	c := &command.Command{
-		Operation: getOperationFromFlag(),
-		Registry:  getFullRegistry(),
-		Quiet:     *flagQuiet || *flagQuietShort,
-		Verbose:   *flagVerbose || *flagVerboseShort,
+		Operation:   getOperationFromFlag(),
+		ExplainPath: *flagExplain,
+		Registry:    getFullRegistry(),
+		Quiet:       *flagQuiet || *flagQuietShort,
+		Verbose:     *flagVerbose || *flagVerboseShort,
+	}
+	if *flagLines != "" {
+		lineRanges, err := yamlfmt.ParseLineRanges(*flagLines)
+		if err != nil {
+			return err
+		}
+		c.LineRanges = lineRanges
 	}
 
 	configData := map[string]any{}
+	c.ConfigSources = command.ConfigSources{}
 	configPath, err := getConfigPath()
 	if err != nil {
 		return err
 	}
 	if configPath != "" {
-		configData, err = readConfig(configPath)
+		configData, c.ConfigSources, err = command.ReadConfigFile(configPath)
 		if err != nil {
 			return err
 		}
@@ -83,12 +106,19 @@ func run() error {
 		flag.Usage()
 		return nil
 	}
+	if err := applyConfigOverrides(configData, c.ConfigSources); err != nil {
+		return err
+	}
+	if err := command.ValidateConfigData(configData, c.ConfigSources, c.Registry); err != nil {
+		return err
+	}
 
 	commandConfig, err := makeCommandConfigFromData(configData)
 	if err != nil {
 		return err
 	}
 	c.Config = commandConfig
+	c.ConfigPath = configPath
 
 	return c.Run()
 }
@@ -0,0 +1,152 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yamlfmt
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CodeBlockError is a YAML code block that couldn't be formatted.
+type CodeBlockError struct {
+	// The line of the opening fence, starting from 1.
+	Line int
+	Err  error
+}
+
+func (e CodeBlockError) Error() string {
+	return fmt.Sprintf("line %d: yaml code block left as is: %v", e.Line, e.Err)
+}
+
+// codeFence is the opening or closing line of a fenced code block.
+type codeFence struct {
+	indent int
+	char   byte
+	length int
+	info   string
+}
+
+// parseCodeFence parses a line that is a run of at least three backticks
+// or tildes, optionally indented by spaces and followed by an info string.
+func parseCodeFence(line []byte) (codeFence, bool) {
+	text := strings.TrimRight(string(line), "\r\n")
+	trimmed := strings.TrimLeft(text, " ")
+	if len(trimmed) < 3 || (trimmed[0] != '`' && trimmed[0] != '~') {
+		return codeFence{}, false
+	}
+	fence := codeFence{indent: len(text) - len(trimmed), char: trimmed[0]}
+	for fence.length < len(trimmed) && trimmed[fence.length] == fence.char {
+		fence.length++
+	}
+	fence.info = strings.TrimSpace(trimmed[fence.length:])
+	// The info string of a backtick fence can't have backticks, or it
+	// would be inline code.
+	if fence.length < 3 || (fence.char == '`' && strings.ContainsRune(fence.info, '`')) {
+		return codeFence{}, false
+	}
+	return fence, true
+}
+
+// closes reports whether the fence closes a block opened by open.
+func (f codeFence) closes(open codeFence) bool {
+	return f.char == open.char && f.length >= open.length && f.info == ""
+}
+
+func (f codeFence) isYaml() bool {
+	lang, _, _ := strings.Cut(f.info, " ")
+	lang = strings.ToLower(lang)
+	return lang == "yaml" || lang == "yml"
+}
+
+// FormatCodeBlocks formats the fenced code blocks of Markdown content that
+// are tagged `yaml` or `yml`, and leaves the rest of content untouched. The
+// contents of a block are taken out of the indentation of its fence, such
+// as in a list item, and put back in it once formatted. If ranges isn't
+// nil, only the blocks that overlap the ranges are formatted.
+//
+// A block that can't be formatted is left as it is, and described in the
+// returned errors, so that the other blocks are still formatted.
+func FormatCodeBlocks(formatter Formatter, content []byte, ranges []LineRange) ([]byte, []CodeBlockError) {
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+
+	var result bytes.Buffer
+	blockErrs := []CodeBlockError{}
+	for i := 0; i < len(lines); i++ {
+		result.Write(lines[i])
+		open, ok := parseCodeFence(lines[i])
+		if !ok {
+			continue
+		}
+		end := i + 1
+		for ; end < len(lines); end++ {
+			if fence, ok := parseCodeFence(lines[end]); ok && fence.closes(open) {
+				break
+			}
+		}
+		// A block that isn't closed runs to the end of the document, and
+		// is left as it is.
+		if end == len(lines) {
+			for _, line := range lines[i+1:] {
+				result.Write(line)
+			}
+			break
+		}
+
+		original := bytes.Join(lines[i+1:end], nil)
+		block := original
+		inRanges := ranges == nil || slices.ContainsFunc(ranges, func(r LineRange) bool { return r.overlaps(i+1, end+1) })
+		if open.isYaml() && inRanges && len(bytes.TrimSpace(original)) > 0 {
+			formatted, err := formatCodeBlock(formatter, lines[i+1:end], open.indent)
+			if err != nil {
+				blockErrs = append(blockErrs, CodeBlockError{Line: i + 1, Err: err})
+			} else {
+				block = withLineBreaksOf(formatted, lines[i])
+			}
+		}
+		result.Write(block)
+		result.Write(lines[end])
+		i = end
+	}
+	return result.Bytes(), blockErrs
+}
+
+func formatCodeBlock(formatter Formatter, lines [][]byte, indent int) ([]byte, error) {
+	var unindented bytes.Buffer
+	for _, line := range lines {
+		trimmed := bytes.TrimLeft(line, " ")
+		unindented.Write(line[min(indent, len(line)-len(trimmed)):])
+	}
+	formatted, err := formatNode(formatter, unindented.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if indent == 0 {
+		return formatted, nil
+	}
+	prefix := []byte(strings.Repeat(" ", indent))
+	var result bytes.Buffer
+	for _, line := range bytes.SplitAfter(formatted, []byte("\n")) {
+		if len(bytes.TrimRight(line, "\r\n")) > 0 {
+			result.Write(prefix)
+		}
+		result.Write(line)
+	}
+	return result.Bytes(), nil
+}
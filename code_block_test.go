@@ -0,0 +1,97 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yamlfmt_test
+
+import (
+	"testing"
+
+	"github.com/google/yamlfmt"
+	"github.com/google/yamlfmt/formatters/basic"
+	"github.com/google/yamlfmt/internal/assert"
+)
+
+func TestFormatCodeBlocks(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		ranges   []yamlfmt.LineRange
+		expected string
+		errLines []int
+	}{
+		{
+			name:     "yaml and yml blocks",
+			content:  "# Title\n\n```yaml\na:   1\n```\n\ntext:   kept\n\n~~~yml\nb: [x,   y]\n~~~\n",
+			expected: "# Title\n\n```yaml\na: 1\n```\n\ntext:   kept\n\n~~~yml\nb: [x, y]\n~~~\n",
+		},
+		{
+			name:     "other languages",
+			content:  "```go\nx :=   1\n```\n```\na:   1\n```\n",
+			expected: "```go\nx :=   1\n```\n```\na:   1\n```\n",
+		},
+		{
+			name:     "indented in a list",
+			content:  "1. Step:\n\n   ```yaml\n   key:    value\n   list:\n   - a\n\n   - b\n   ```\n",
+			expected: "1. Step:\n\n   ```yaml\n   key: value\n   list:\n     - a\n     - b\n   ```\n",
+		},
+		{
+			name:     "info string after the language",
+			content:  "```YAML title=\"x.yaml\"\na:   1\n```\n",
+			expected: "```YAML title=\"x.yaml\"\na: 1\n```\n",
+		},
+		{
+			name:     "fence inside a longer fence",
+			content:  "````markdown\n```yaml\na:   1\n```\n````\n",
+			expected: "````markdown\n```yaml\na:   1\n```\n````\n",
+		},
+		{
+			name:     "unclosed block",
+			content:  "```yaml\na:   1\n",
+			expected: "```yaml\na:   1\n",
+		},
+		{
+			name:     "crlf",
+			content:  "```yaml\r\na:   1\r\n```\r\n",
+			expected: "```yaml\r\na: 1\r\n```\r\n",
+		},
+		{
+			name:     "invalid block is left as is",
+			content:  "```yaml\na: [\n```\n\n```yaml\nb:   2\n```\n",
+			expected: "```yaml\na: [\n```\n\n```yaml\nb: 2\n```\n",
+			errLines: []int{1},
+		},
+		{
+			name:     "line ranges",
+			content:  "```yaml\na:   1\n```\n```yaml\nb:   2\n```\n",
+			ranges:   []yamlfmt.LineRange{{Start: 5, End: 5}},
+			expected: "```yaml\na:   1\n```\n```yaml\nb: 2\n```\n",
+		},
+	}
+	formatter, err := (&basic.BasicFormatterFactory{}).NewFormatter(map[string]any{"include_document_start": true})
+	assert.NilErr(t, err)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			formatted, blockErrs := yamlfmt.FormatCodeBlocks(formatter, []byte(tc.content), tc.ranges)
+			assert.Equal(t, tc.expected, string(formatted))
+			errLines := []int{}
+			for _, blockErr := range blockErrs {
+				errLines = append(errLines, blockErr.Line)
+			}
+			if tc.errLines == nil {
+				tc.errLines = []int{}
+			}
+			assert.SliceEqual(t, tc.errLines, errLines)
+		})
+	}
+}
@@ -19,17 +19,26 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"runtime"
+	"slices"
+	"strings"
 
 	"github.com/google/yamlfmt"
 	"github.com/google/yamlfmt/engine"
+	"github.com/google/yamlfmt/formatters/basic"
+	"github.com/google/yamlfmt/internal/collections"
+	"github.com/google/yamlfmt/internal/editorconfig"
 	"github.com/google/yamlfmt/pkg/yaml"
 	"github.com/mitchellh/mapstructure"
 )
 
+var ErrChangedLinesWithoutChangedSince = errors.New("changed_lines requires changed_since to be set")
+
 type FormatterConfig struct {
-	Type              string         `mapstructure:"type"`
-	FormatterSettings map[string]any `mapstructure:",remain"`
+	Type              string         `mapstructure:"type" yaml:"type,omitempty"`
+	FormatterSettings map[string]any `mapstructure:",remain" yaml:",inline"`
 }
 
 // NewFormatterConfig returns an empty formatter config with all fields initialized.
@@ -38,46 +47,120 @@ func NewFormatterConfig() *FormatterConfig {
 }
 
 type Config struct {
-	Extensions        []string                  `mapstructure:"extensions"`
-	MatchType         yamlfmt.MatchType         `mapstructure:"match_type"`
-	Include           []string                  `mapstructure:"include"`
-	Exclude           []string                  `mapstructure:"exclude"`
-	RegexExclude      []string                  `mapstructure:"regex_exclude"`
-	FormatterConfig   *FormatterConfig          `mapstructure:"formatter,omitempty"`
-	Doublestar        bool                      `mapstructure:"doublestar"`
-	ContinueOnError   bool                      `mapstructure:"continue_on_error"`
-	LineEnding        yamlfmt.LineBreakStyle    `mapstructure:"line_ending"`
-	GitignoreExcludes bool                      `mapstructure:"gitignore_excludes"`
-	GitignorePath     string                    `mapstructure:"gitignore_path"`
-	OutputFormat      engine.EngineOutputFormat `mapstructure:"output_format"`
+	Extensions            []string                  `mapstructure:"extensions"`
+	Filenames             []string                  `mapstructure:"filenames"`
+	SniffContent          bool                      `mapstructure:"sniff_content"`
+	FrontMatterExtensions []string                  `mapstructure:"front_matter_extensions"`
+	CodeBlockExtensions   []string                  `mapstructure:"code_block_extensions"`
+	MatchType             yamlfmt.MatchType         `mapstructure:"match_type"`
+	Include               []string                  `mapstructure:"include"`
+	Exclude               []string                  `mapstructure:"exclude"`
+	RegexExclude          []string                  `mapstructure:"regex_exclude"`
+	ExcludeGenerated      bool                      `mapstructure:"exclude_generated"`
+	MaxFileSize           int                       `mapstructure:"max_file_size"`
+	ExcludeBinary         bool                      `mapstructure:"exclude_binary"`
+	ExcludeInvalidYaml    bool                      `mapstructure:"exclude_invalid_yaml"`
+	FormatterConfig       *FormatterConfig          `mapstructure:"formatter,omitempty"`
+	Doublestar            bool                      `mapstructure:"doublestar"`
+	ContinueOnError       bool                      `mapstructure:"continue_on_error"`
+	LineEnding            yamlfmt.LineBreakStyle    `mapstructure:"line_ending"`
+	BOM                   yamlfmt.BOMMode           `mapstructure:"bom"`
+	GitignoreExcludes     bool                      `mapstructure:"gitignore_excludes"`
+	GitignorePath         string                    `mapstructure:"gitignore_path"`
+	OutputFormat          engine.EngineOutputFormat `mapstructure:"output_format"`
+	Overrides             []*OverrideConfig         `mapstructure:"overrides,omitempty"`
+	NestedConfigs         bool                      `mapstructure:"nested_configs"`
+	Inherit               bool                      `mapstructure:"inherit,omitempty"`
+	EditorConfig          bool                      `mapstructure:"editorconfig"`
+	DisableStrictConfig   bool                      `mapstructure:"disable_strict_config"`
+	GitUntracked          bool                      `mapstructure:"git_untracked"`
+	ChangedSince          string                    `mapstructure:"changed_since"`
+	ChangedLines          bool                      `mapstructure:"changed_lines"`
+	Symlinks              yamlfmt.SymlinkPolicy     `mapstructure:"symlinks"`
+}
+
+// DefaultConfig returns the config used when a setting isn't provided by
+// the config file or a flag.
+func DefaultConfig() *Config {
+	lineEnding := yamlfmt.LineBreakStyleLF
+	if runtime.GOOS == "windows" {
+		lineEnding = yamlfmt.LineBreakStyleCRLF
+	}
+	return &Config{
+		Extensions:      []string{"yaml", "yml"},
+		MatchType:       yamlfmt.MatchTypeStandard,
+		FormatterConfig: &FormatterConfig{Type: basic.BasicFormatterType, FormatterSettings: map[string]any{}},
+		LineEnding:      lineEnding,
+		BOM:             yamlfmt.BOMModePreserve,
+		GitignorePath:   ".gitignore",
+		OutputFormat:    engine.EngineOutputDefault,
+		Symlinks:        yamlfmt.SymlinkPolicyFollowWithinRoot,
+	}
 }
 
 type Command struct {
 	Operation yamlfmt.Operation
 	Registry  *yamlfmt.Registry
 	Config    *Config
-	Quiet     bool
-	Verbose   bool
+	// The path of the config file that Config was read from, if any.
+	ConfigPath string
+	// Where each value in the config file came from.
+	ConfigSources ConfigSources
+	// The path to explain with OperationExplain.
+	ExplainPath string
+	// When set, only the top level nodes that overlap these lines are
+	// formatted.
+	LineRanges []yamlfmt.LineRange
+	Quiet      bool
+	Verbose    bool
+
+	nestedConfigs *nestedConfigResolver
+	editorConfig  *editorconfig.Resolver
+	// Errors in `!yamlfmt!:set` metadata found while working out the
+	// formatter config for each path.
+	metadataErrs collections.Errors
+	// The `!yamlfmt!:set` metadata of each path that the content analysis
+	// read, so that the files aren't read again for it.
+	setMetadata map[string][]yamlfmt.Metadata
 }
 
 func (c *Command) Run() error {
-	formatter, err := c.getFormatter()
-	if err != nil {
+	if err := c.validateConfig(); err != nil {
 		return err
 	}
 
-	lineSepChar, err := c.Config.LineEnding.Separator()
+	// Explaining a path runs each step of path collection for that
+	// path alone, so it doesn't need the engine.
+	if c.Operation == yamlfmt.OperationExplain {
+		return c.explain(os.Stdout, c.ExplainPath)
+	}
+
+	formatter, err := c.getFormatter()
 	if err != nil {
 		return err
 	}
 
+	// With automatic line endings, the engine detects the
+	// separator from each file instead.
+	lineSepChar := ""
+	if c.Config.LineEnding != yamlfmt.LineBreakStyleAuto {
+		lineSepChar, err = c.Config.LineEnding.Separator()
+		if err != nil {
+			return err
+		}
+	}
+
 	eng := &engine.ConsecutiveEngine{
-		LineSepCharacter: lineSepChar,
-		Formatter:        formatter,
-		Quiet:            c.Quiet,
-		Verbose:          c.Verbose,
-		ContinueOnError:  c.Config.ContinueOnError,
-		OutputFormat:     c.Config.OutputFormat,
+		LineSepCharacter:      lineSepChar,
+		Formatter:             formatter,
+		Quiet:                 c.Quiet,
+		Verbose:               c.Verbose,
+		ContinueOnError:       c.Config.ContinueOnError,
+		OutputFormat:          c.Config.OutputFormat,
+		BOM:                   c.Config.BOM,
+		LineRanges:            c.LineRanges,
+		FrontMatterExtensions: c.Config.FrontMatterExtensions,
+		CodeBlockExtensions:   c.Config.CodeBlockExtensions,
 	}
 
 	var paths []string
@@ -87,7 +170,7 @@ func (c *Command) Run() error {
 	if c.Operation == yamlfmt.OperationStdin {
 		paths = []string{}
 	} else {
-		collectedPaths, err := c.collectPaths()
+		collectedPaths, excludedPaths, err := c.collectPaths()
 		if err != nil {
 			return err
 		}
@@ -96,12 +179,63 @@ func (c *Command) Run() error {
 			if err != nil {
 				return err
 			}
+			excludedPaths, err = c.addExcludedPaths(excludedPaths, collectedPaths, newPaths, "gitignore", func(path string) (string, error) {
+				_, reason, err := yamlfmt.ExplainGitignore(c.Config.GitignorePath, path)
+				return reason, err
+			})
+			if err != nil {
+				return err
+			}
 			collectedPaths = newPaths
 		}
-		paths, err = c.analyzePaths(collectedPaths)
+		newPaths, err := yamlfmt.ExcludeWithYamlfmtIgnore(collectedPaths)
+		if err != nil {
+			return err
+		}
+		excludedPaths, err = c.addExcludedPaths(excludedPaths, collectedPaths, newPaths, "yamlfmtignore", func(path string) (string, error) {
+			_, reason, err := yamlfmt.ExplainYamlfmtIgnore(path)
+			return reason, err
+		})
 		if err != nil {
-			fmt.Printf("path analysis found the following errors:\n%v", err)
-			fmt.Println("Continuing...")
+			return err
+		}
+		collectedPaths = newPaths
+		if c.Config.ChangedSince != "" {
+			newPaths, unchanged, err := yamlfmt.ExcludeUnchanged(c.Config.ChangedSince, collectedPaths)
+			if err != nil {
+				return err
+			}
+			if c.reportsExcludedPaths() {
+				for _, path := range unchanged {
+					excludedPaths = append(excludedPaths, yamlfmt.ExcludedPath{Path: path.Path, Reason: "changed: " + path.Reason})
+				}
+			}
+			collectedPaths = newPaths
+		}
+		paths, excludedPaths, err = c.analyzePaths(collectedPaths, excludedPaths)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "path analysis found the following errors:\n%v", err)
+			fmt.Fprintln(os.Stderr, "Continuing...")
+		}
+		slices.SortFunc(excludedPaths, func(a, b yamlfmt.ExcludedPath) int {
+			return strings.Compare(a.Path, b.Path)
+		})
+		eng.ExcludedPaths = excludedPaths
+		eng.PathFormatters, err = c.makePathFormatters(paths)
+		if err != nil {
+			return err
+		}
+		if len(c.metadataErrs) > 0 {
+			// Written to stderr so that it doesn't mix with the
+			// formatted output or the JSON report on stdout.
+			fmt.Fprintf(os.Stderr, "metadata settings have the following errors:\n%v", c.metadataErrs.Combine())
+			fmt.Fprintln(os.Stderr, "Continuing...")
+		}
+		if c.Config.ChangedLines {
+			eng.PathLineRanges, err = yamlfmt.ChangedLineRanges(c.Config.ChangedSince, paths)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -119,7 +253,9 @@ func (c *Command) Run() error {
 		if err != nil {
 			return err
 		}
-		if out != nil {
+		if passed, ok := out.(engine.LintPassedOutput); ok {
+			fmt.Print(passed)
+		} else if out != nil {
 			// This will be picked up by log.Fatal in main() and
 			// cause an exit code of 1, which is a critical
 			// component of the lint functionality.
@@ -146,48 +282,104 @@ func (c *Command) Run() error {
 		}
 		fmt.Printf("%s", out)
 	case yamlfmt.OperationPrintConfig:
-		commandConfig := map[string]any{}
-		err = mapstructure.Decode(c.Config, &commandConfig)
-		if err != nil {
-			return err
-		}
-		delete(commandConfig, "formatter")
-		out, err := yaml.Marshal(commandConfig)
-		if err != nil {
-			return err
-		}
-		fmt.Printf("%s", out)
+		return c.printConfig(formatter)
+	}
 
-		formatterConfigMap, err := formatter.ConfigMap()
-		if err != nil {
-			return err
-		}
-		out, err = yaml.Marshal(map[string]any{
-			"formatter": formatterConfigMap,
-		})
-		if err != nil {
-			return err
-		}
-		fmt.Printf("%s", out)
+	return nil
+}
+
+// validateConfig checks the settings that would otherwise only fail
+// after paths are collected, or once for every file.
+func (c *Command) validateConfig() error {
+	if err := c.Config.BOM.Validate(); err != nil {
+		return err
+	}
+	if c.Config.ChangedLines && c.Config.ChangedSince == "" {
+		return ErrChangedLinesWithoutChangedSince
+	}
+	return nil
+}
+
+func (c *Command) printConfig(formatter yamlfmt.Formatter) error {
+	commandConfig := map[string]any{}
+	err := mapstructure.Decode(c.Config, &commandConfig)
+	if err != nil {
+		return err
 	}
+	delete(commandConfig, "formatter")
+	out, err := c.marshalConfigWithSources(commandConfig)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s", out)
 
+	formatterConfigMap, err := formatter.ConfigMap()
+	if err != nil {
+		return err
+	}
+	out, err = c.marshalConfigWithSources(map[string]any{
+		"formatter": formatterConfigMap,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s", out)
 	return nil
 }
 
+// marshalConfigWithSources marshals configData to yaml. When the config
+// was put together from more than one source, each value is commented
+// with where it came from.
+func (c *Command) marshalConfigWithSources(configData map[string]any) ([]byte, error) {
+	if c.ConfigSources.Distinct() < 2 {
+		return yaml.Marshal(configData)
+	}
+	var node yaml.Node
+	if err := node.Encode(configData); err != nil {
+		return nil, err
+	}
+	annotateConfigSources(&node, "", c.ConfigSources)
+	return yaml.Marshal(&node)
+}
+
+func annotateConfigSources(node *yaml.Node, prefix string, sources ConfigSources) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		key := prefix + keyNode.Value
+		if source, ok := sources[key]; ok {
+			keyNode.LineComment = "from " + source
+			continue
+		}
+		annotateConfigSources(valueNode, key+".", sources)
+	}
+}
+
 func (c *Command) getFormatter() (yamlfmt.Formatter, error) {
+	return c.newFormatter(c.Config.FormatterConfig)
+}
+
+func (c *Command) newFormatter(fc *FormatterConfig) (yamlfmt.Formatter, error) {
 	var factoryType string
+	var settings map[string]any
 
 	// In the existing codepaths, this value is always set. But
 	// it's a habit of mine to check anything that can possibly be nil
 	// if I remember that to be the case. :)
-	if c.Config.FormatterConfig != nil {
-		factoryType = c.Config.FormatterConfig.Type
+	if fc != nil {
+		factoryType = fc.Type
+		settings = maps.Clone(fc.FormatterSettings)
+		if settings == nil {
+			settings = map[string]any{}
+		}
 
 		// The line ending set within the formatter settings takes precedence over setting
 		// it from the top level config. If it's not set in formatter settings, then
 		// we use the value from the top level.
-		if _, ok := c.Config.FormatterConfig.FormatterSettings["line_ending"]; !ok {
-			c.Config.FormatterConfig.FormatterSettings["line_ending"] = c.Config.LineEnding
+		if _, ok := settings["line_ending"]; !ok {
+			settings["line_ending"] = c.Config.LineEnding
 		}
 	}
 
@@ -195,33 +387,85 @@ func (c *Command) getFormatter() (yamlfmt.Formatter, error) {
 	if err != nil {
 		return nil, err
 	}
-	return factory.NewFormatter(c.Config.FormatterConfig.FormatterSettings)
+	return factory.NewFormatter(settings)
 }
 
-func (c *Command) collectPaths() ([]string, error) {
+// collectPaths collects the paths to format. When excluded paths are
+// reported, it also returns the paths that the exclude patterns of the
+// path collector left out.
+func (c *Command) collectPaths() ([]string, []yamlfmt.ExcludedPath, error) {
 	collector, err := c.makePathCollector()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	paths, err := collector.CollectPaths()
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return collector.CollectPaths()
+	excludedPaths := []yamlfmt.ExcludedPath{}
+	if reporter, ok := collector.(yamlfmt.ExcludedPathReporter); ok && c.reportsExcludedPaths() {
+		for _, excluded := range reporter.ExcludedPaths() {
+			excluded.Reason = "paths: " + excluded.Reason
+			excludedPaths = append(excludedPaths, excluded)
+		}
+	}
+	return paths, excludedPaths, nil
 }
 
-func (c *Command) analyzePaths(paths []string) ([]string, error) {
+func (c *Command) analyzePaths(paths []string, excludedPaths []yamlfmt.ExcludedPath) ([]string, []yamlfmt.ExcludedPath, error) {
 	analyzer, err := c.makeAnalyzer()
 	if err != nil {
-		return nil, err
+		return nil, excludedPaths, err
+	}
+	includePaths, excluded, err := analyzer.ExcludePathsByContent(paths)
+	explainer, ok := analyzer.(yamlfmt.ContentExplainer)
+	if !ok || !c.reportsExcludedPaths() {
+		return includePaths, excludedPaths, err
+	}
+	for _, path := range excluded {
+		// Errors reading the content were already reported above.
+		reason, _ := explainer.ExcludeReason(path)
+		excludedPaths = append(excludedPaths, yamlfmt.ExcludedPath{Path: path, Reason: "content: excluded by " + reason})
+	}
+	return includePaths, excludedPaths, err
+}
+
+// reportsExcludedPaths reports whether the engine output lists the paths
+// that were excluded from formatting. Finding the reason for each one
+// isn't free, so it is only done when they are listed.
+func (c *Command) reportsExcludedPaths() bool {
+	return c.Verbose || c.Config.OutputFormat == engine.EngineOutputJSON
+}
+
+// addExcludedPaths adds the paths in before that aren't in after to
+// excludedPaths, with the reason that explain gives for each one,
+// labelled by step. Nothing is added unless excluded paths are reported.
+func (c *Command) addExcludedPaths(excludedPaths []yamlfmt.ExcludedPath, before []string, after []string, step string, explain func(path string) (string, error)) ([]yamlfmt.ExcludedPath, error) {
+	if !c.reportsExcludedPaths() {
+		return excludedPaths, nil
+	}
+	kept := collections.SliceToSet(after)
+	for _, path := range before {
+		if kept.Contains(path) {
+			continue
+		}
+		reason, err := explain(path)
+		if err != nil {
+			return nil, err
+		}
+		excludedPaths = append(excludedPaths, yamlfmt.ExcludedPath{Path: path, Reason: step + ": " + reason})
 	}
-	includePaths, _, err := analyzer.ExcludePathsByContent(paths)
-	return includePaths, err
+	return excludedPaths, nil
 }
 
 func (c *Command) makePathCollector() (yamlfmt.PathCollector, error) {
 	switch c.Config.MatchType {
 	case yamlfmt.MatchTypeDoublestar:
 		return &yamlfmt.DoublestarCollector{
-			Include: c.Config.Include,
-			Exclude: c.Config.Exclude,
+			Include:  c.Config.Include,
+			Exclude:  c.Config.Exclude,
+			Symlinks: c.Config.Symlinks,
 		}, nil
 	case yamlfmt.MatchTypeGitignore:
 		files := c.Config.Include
@@ -233,19 +477,81 @@ func (c *Command) makePathCollector() (yamlfmt.PathCollector, error) {
 		if err != nil {
 			return nil, fmt.Errorf("NewPatternFile(%q): %w", files, err)
 		}
+		patternFile.Symlinks = c.Config.Symlinks
 
 		return patternFile, nil
+	case yamlfmt.MatchTypeGit:
+		return &yamlfmt.GitCollector{
+			Include:      c.Config.Include,
+			Exclude:      c.Config.Exclude,
+			Extensions:   c.collectedExtensions(),
+			Filenames:    c.Config.Filenames,
+			SniffContent: c.Config.SniffContent,
+			SniffFilters: c.ignoreFilters(),
+			Untracked:    c.Config.GitUntracked,
+			Symlinks:     c.Config.Symlinks,
+		}, nil
 	default:
 		return &yamlfmt.FilepathCollector{
-			Include:    c.Config.Include,
-			Exclude:    c.Config.Exclude,
-			Extensions: c.Config.Extensions,
+			Include:      c.Config.Include,
+			Exclude:      c.Config.Exclude,
+			Extensions:   c.collectedExtensions(),
+			Filenames:    c.Config.Filenames,
+			SniffContent: c.Config.SniffContent,
+			SniffFilters: c.ignoreFilters(),
+			Symlinks:     c.Config.Symlinks,
 		}, nil
 	}
 }
 
+// ignoreFilters returns the filters for the ignore files that Run checks
+// the collected paths against, so that the path collectors can leave out
+// ignored files before sniffing their content.
+func (c *Command) ignoreFilters() []yamlfmt.PathFilter {
+	filters := []yamlfmt.PathFilter{}
+	if c.Config.GitignoreExcludes {
+		filters = append(filters, func(paths []string) ([]string, error) {
+			return yamlfmt.ExcludeWithGitignore(c.Config.GitignorePath, paths)
+		})
+	}
+	return append(filters, yamlfmt.ExcludeWithYamlfmtIgnore)
+}
+
+// collectedExtensions returns the extensions of the files to collect, which
+// include the extensions of files that only have their front matter or
+// code blocks formatted.
+func (c *Command) collectedExtensions() []string {
+	extensions := append(slices.Clone(c.Config.Extensions), c.Config.FrontMatterExtensions...)
+	return append(extensions, c.Config.CodeBlockExtensions...)
+}
+
+// makeAnalyzer chains the content analyzers that are configured. The file
+// size is checked first, so that files that are too large are never read.
 func (c *Command) makeAnalyzer() (yamlfmt.ContentAnalyzer, error) {
-	return yamlfmt.NewBasicContentAnalyzer(c.Config.RegexExclude)
+	basicAnalyzer, err := yamlfmt.NewBasicContentAnalyzer(c.Config.RegexExclude)
+	if err != nil {
+		return nil, err
+	}
+	checks := []yamlfmt.ContentCheck{}
+	if c.Config.MaxFileSize > 0 {
+		checks = append(checks, yamlfmt.CheckMaxFileSize(c.Config.MaxFileSize))
+	}
+	if c.Config.ExcludeBinary {
+		checks = append(checks, yamlfmt.CheckBinary)
+	}
+	checks = append(checks, basicAnalyzer.Check)
+	if c.Config.ExcludeGenerated {
+		checks = append(checks, yamlfmt.CheckGenerated)
+	}
+	if c.Config.ExcludeInvalidYaml {
+		// Only the front matter or code blocks of these files are yaml.
+		notYaml := append(slices.Clone(c.Config.FrontMatterExtensions), c.Config.CodeBlockExtensions...)
+		checks = append(checks, yamlfmt.CheckValidYaml(notYaml))
+	}
+	// Not a check, but the file has been read by now.
+	c.setMetadata = map[string][]yamlfmt.Metadata{}
+	checks = append(checks, c.recordSetMetadata)
+	return yamlfmt.ContentAnalyzerChain{Checks: checks}, nil
 }
 
 func readFromStdin() ([]byte, error) {
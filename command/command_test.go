@@ -15,11 +15,16 @@
 package command
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/yamlfmt"
 	"github.com/google/yamlfmt/formatters/basic"
 	"github.com/google/yamlfmt/internal/assert"
+	"github.com/google/yamlfmt/internal/tempfile"
 )
 
 // This test asserts the proper behaviour for `line_ending` settings specified
@@ -44,3 +49,455 @@ func TestLineEndingFormatterVsGlobal(t *testing.T) {
 	formatterLineEnding := configMap["line_ending"].(yamlfmt.LineBreakStyle)
 	assert.Assert(t, formatterLineEnding == yamlfmt.LineBreakStyleLF, "expected formatter's line ending to be lf")
 }
+
+func TestOverrides(t *testing.T) {
+	c := &Command{
+		Config: &Config{
+			LineEnding: "lf",
+			FormatterConfig: &FormatterConfig{
+				FormatterSettings: map[string]any{
+					"indent": 2,
+				},
+			},
+			Overrides: []*OverrideConfig{
+				{
+					Include: []string{"charts/**/values.yaml"},
+					FormatterConfig: &FormatterConfig{
+						FormatterSettings: map[string]any{"indent": 4},
+					},
+				},
+				{
+					Include: []string{".github/workflows/*.yaml"},
+					Exclude: []string{".github/workflows/generated.yaml"},
+					FormatterConfig: &FormatterConfig{
+						FormatterSettings: map[string]any{"include_document_start": true},
+					},
+				},
+			},
+		},
+		Registry: yamlfmt.NewFormatterRegistry(&basic.BasicFormatterFactory{}),
+	}
+
+	paths := []string{
+		"charts/app/values.yaml",
+		"charts/other/values.yaml",
+		".github/workflows/ci.yaml",
+		".github/workflows/generated.yaml",
+		"k8s/deployment.yaml",
+	}
+	pathFormatters, err := c.makePathFormatters(paths)
+	assert.NilErr(t, err)
+	assert.Equal(t, 3, len(pathFormatters))
+	assert.Assert(t, pathFormatters["charts/app/values.yaml"] == pathFormatters["charts/other/values.yaml"], "expected paths with the same config to share a formatter")
+
+	chartsConfig, err := pathFormatters["charts/app/values.yaml"].ConfigMap()
+	assert.NilErr(t, err)
+	assert.Equal(t, 4, chartsConfig["indent"].(int))
+
+	workflowConfig, err := pathFormatters[".github/workflows/ci.yaml"].ConfigMap()
+	assert.NilErr(t, err)
+	assert.Equal(t, 2, workflowConfig["indent"].(int))
+	assert.Equal(t, true, workflowConfig["include_document_start"].(bool))
+}
+
+func TestChangedLinesWithoutChangedSince(t *testing.T) {
+	c := &Command{
+		Operation: yamlfmt.OperationFormat,
+		Config: &Config{
+			Include:         []string{filepath.Join(t.TempDir(), "missing")},
+			ChangedLines:    true,
+			FormatterConfig: NewFormatterConfig(),
+		},
+		Registry: yamlfmt.NewFormatterRegistry(&basic.BasicFormatterFactory{}),
+	}
+	err := c.Run()
+	assert.Assert(t, errors.Is(err, ErrChangedLinesWithoutChangedSince), "expected ErrChangedLinesWithoutChangedSince before paths are collected, got: %v", err)
+}
+
+func TestOverrideWithoutInclude(t *testing.T) {
+	override := &OverrideConfig{}
+	_, err := override.Matches("x.yaml", "")
+	assert.NotNilErr(t, err)
+}
+
+func TestOverrideMatchesBaseDir(t *testing.T) {
+	tempDir := t.TempDir()
+	override := &OverrideConfig{
+		Include: []string{"sub/**", filepath.Join(tempDir, "abs", "*.yaml")},
+		Exclude: []string{"sub/generated.yaml"},
+	}
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{path: filepath.Join(tempDir, "sub", "f.yaml"), expected: true},
+		{path: filepath.Join(tempDir, "sub", "generated.yaml"), expected: false},
+		{path: filepath.Join(tempDir, "abs", "f.yaml"), expected: true},
+		{path: filepath.Join(tempDir, "f.yaml"), expected: false},
+		// Patterns in the base directory don't reach outside of it.
+		{path: filepath.Join(filepath.Dir(tempDir), "sub", "f.yaml"), expected: false},
+	}
+	for _, tc := range testCases {
+		match, err := override.Matches(tc.path, tempDir)
+		assert.NilErr(t, err)
+		assert.Assert(t, match == tc.expected, "expected Matches(%s) to be %v", tc.path, tc.expected)
+	}
+}
+
+func TestNestedConfigs(t *testing.T) {
+	tempDir := t.TempDir()
+	files := tempfile.Paths{
+		{BasePath: tempDir, FilePath: ".yamlfmt", Content: []byte("nested_configs: true\n")},
+		{BasePath: tempDir, FilePath: "sub", IsDir: true},
+		{BasePath: tempDir, FilePath: "sub/.yamlfmt", Content: []byte("formatter:\n  indent: 4\n")},
+		{BasePath: tempDir, FilePath: "sub/deeper", IsDir: true},
+		{BasePath: tempDir, FilePath: "sub/deeper/.yamlfmt", Content: []byte("inherit: true\nformatter:\n  include_document_start: true\n")},
+		{BasePath: tempDir, FilePath: "inheriting", IsDir: true},
+		{BasePath: tempDir, FilePath: "inheriting/.yamlfmt", Content: []byte("inherit: true\nformatter:\n  indent: 6\n")},
+	}
+	assert.NilErr(t, files.CreateAll())
+
+	c := &Command{
+		Config: &Config{
+			LineEnding:    "lf",
+			NestedConfigs: true,
+			FormatterConfig: &FormatterConfig{
+				FormatterSettings: map[string]any{
+					"retain_line_breaks": true,
+				},
+			},
+			Overrides: []*OverrideConfig{
+				{
+					Include: []string{"**/override.yaml"},
+					FormatterConfig: &FormatterConfig{
+						FormatterSettings: map[string]any{"max_line_length": 80},
+					},
+				},
+			},
+		},
+		ConfigPath: filepath.Join(tempDir, ".yamlfmt"),
+		Registry:   yamlfmt.NewFormatterRegistry(&basic.BasicFormatterFactory{}),
+	}
+
+	rootPath := filepath.Join(tempDir, "x.yaml")
+	subPath := filepath.Join(tempDir, "sub", "x.yaml")
+	subOverridePath := filepath.Join(tempDir, "sub", "override.yaml")
+	deeperPath := filepath.Join(tempDir, "sub", "deeper", "x.yaml")
+	inheritingOverridePath := filepath.Join(tempDir, "inheriting", "override.yaml")
+	pathFormatters, err := c.makePathFormatters([]string{
+		rootPath,
+		subPath,
+		subOverridePath,
+		deeperPath,
+		inheritingOverridePath,
+	})
+	assert.NilErr(t, err)
+
+	_, ok := pathFormatters[rootPath]
+	assert.Assert(t, !ok, "expected the root config to apply to %s", rootPath)
+
+	configFor := func(path string) map[string]any {
+		t.Helper()
+		formatter, ok := pathFormatters[path]
+		assert.Assert(t, ok, "expected a nested config to apply to %s", path)
+		configMap, err := formatter.ConfigMap()
+		assert.NilErr(t, err)
+		return configMap
+	}
+
+	subConfig := configFor(subPath)
+	assert.Equal(t, 4, subConfig["indent"].(int))
+	assert.Equal(t, false, subConfig["retain_line_breaks"].(bool))
+
+	// The root overrides don't apply because sub/.yamlfmt doesn't inherit.
+	subOverrideConfig := configFor(subOverridePath)
+	assert.Equal(t, 0, subOverrideConfig["max_line_length"].(int))
+
+	deeperConfig := configFor(deeperPath)
+	assert.Equal(t, 4, deeperConfig["indent"].(int))
+	assert.Equal(t, true, deeperConfig["include_document_start"].(bool))
+
+	inheritingConfig := configFor(inheritingOverridePath)
+	assert.Equal(t, 6, inheritingConfig["indent"].(int))
+	assert.Equal(t, true, inheritingConfig["retain_line_breaks"].(bool))
+	assert.Equal(t, 80, inheritingConfig["max_line_length"].(int))
+
+	// A config file next to a path outside of the working directory and
+	// the root config's directory is never used.
+	outsideDir := t.TempDir()
+	outsideFiles := tempfile.Paths{
+		{BasePath: outsideDir, FilePath: ".yamlfmt", Content: []byte("formatter:\n  indent: 8\n")},
+	}
+	assert.NilErr(t, outsideFiles.CreateAll())
+	outsidePath := filepath.Join(outsideDir, "x.yaml")
+	pathFormatters, err = c.makePathFormatters([]string{outsidePath})
+	assert.NilErr(t, err)
+	_, ok = pathFormatters[outsidePath]
+	assert.Assert(t, !ok, "expected the root config to apply to %s", outsidePath)
+}
+
+func TestNestedConfigRootOnlyKeys(t *testing.T) {
+	tempDir := t.TempDir()
+	files := tempfile.Paths{
+		{BasePath: tempDir, FilePath: ".yamlfmt", Content: []byte("nested_configs: true\n")},
+		{BasePath: tempDir, FilePath: "strict", IsDir: true},
+		{BasePath: tempDir, FilePath: "strict/.yamlfmt", Content: []byte("formatter:\n  indent: 4\nexclude:\n  - vendor\n")},
+		{BasePath: tempDir, FilePath: "lenient", IsDir: true},
+		{BasePath: tempDir, FilePath: "lenient/.yamlfmt", Content: []byte("disable_strict_config: true\nexclude:\n  - vendor\n")},
+	}
+	assert.NilErr(t, files.CreateAll())
+
+	c := &Command{
+		Config: &Config{
+			NestedConfigs:   true,
+			FormatterConfig: NewFormatterConfig(),
+		},
+		ConfigPath: filepath.Join(tempDir, ".yamlfmt"),
+		Registry:   yamlfmt.NewFormatterRegistry(&basic.BasicFormatterFactory{}),
+	}
+
+	_, err := c.makePathFormatters([]string{filepath.Join(tempDir, "strict", "x.yaml")})
+	assert.NotNilErr(t, err)
+	expected := filepath.Join(tempDir, "strict", ".yamlfmt") + `:3: key "exclude" only applies to the root config`
+	assert.Assert(t, strings.Contains(err.Error(), expected), "expected error to contain %q, got: %v", expected, err)
+
+	_, err = c.makePathFormatters([]string{filepath.Join(tempDir, "lenient", "x.yaml")})
+	assert.NilErr(t, err)
+}
+
+func TestEditorConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	files := tempfile.Paths{
+		{BasePath: tempDir, FilePath: ".editorconfig", Content: []byte(`root = true
+
+[*.yaml]
+indent_size = 4
+end_of_line = crlf
+insert_final_newline = true
+max_line_length = 100
+`)},
+		{BasePath: tempDir, FilePath: "a.yaml"},
+	}
+	assert.NilErr(t, files.CreateAll())
+	path := filepath.Join(tempDir, "a.yaml")
+
+	c := &Command{
+		Config: &Config{
+			LineEnding:   "lf",
+			EditorConfig: true,
+			FormatterConfig: &FormatterConfig{
+				FormatterSettings: map[string]any{
+					"max_line_length": 80,
+				},
+			},
+		},
+		ConfigSources: ConfigSources{"line_ending": ".yamlfmt"},
+		Registry:      yamlfmt.NewFormatterRegistry(&basic.BasicFormatterFactory{}),
+	}
+	pathFormatters, err := c.makePathFormatters([]string{path})
+	assert.NilErr(t, err)
+	formatter, ok := pathFormatters[path]
+	assert.Assert(t, ok, "expected editorconfig settings to apply to %s", path)
+
+	configMap, err := formatter.ConfigMap()
+	assert.NilErr(t, err)
+	assert.Equal(t, 4, configMap["indent"].(int))
+	assert.Equal(t, true, configMap["eof_newline"].(bool))
+	// Explicit yamlfmt settings take precedence.
+	assert.Equal(t, 80, configMap["max_line_length"].(int))
+	assert.Equal(t, yamlfmt.LineBreakStyleLF, configMap["line_ending"].(yamlfmt.LineBreakStyle))
+}
+
+func TestMetadataSettings(t *testing.T) {
+	tempDir := t.TempDir()
+	files := tempfile.Paths{
+		{BasePath: tempDir, FilePath: "a.yaml", Content: []byte(`# !yamlfmt!:set:max_line_length=0
+# !yamlfmt!:set:indnt=4
+# !yamlfmt!:set:indent=four
+a: 1
+`)},
+		{BasePath: tempDir, FilePath: "b.yaml", Content: []byte("a: 1\n")},
+	}
+	assert.NilErr(t, files.CreateAll())
+	pathA := filepath.Join(tempDir, "a.yaml")
+	pathB := filepath.Join(tempDir, "b.yaml")
+
+	c := &Command{
+		Config: &Config{
+			FormatterConfig: &FormatterConfig{
+				FormatterSettings: map[string]any{
+					"max_line_length": 80,
+					"indent":          4,
+				},
+			},
+			Overrides: []*OverrideConfig{
+				{
+					Include:         []string{filepath.Join(tempDir, "*.yaml")},
+					FormatterConfig: &FormatterConfig{FormatterSettings: map[string]any{"max_line_length": 100}},
+				},
+			},
+		},
+		Registry: yamlfmt.NewFormatterRegistry(&basic.BasicFormatterFactory{}),
+	}
+	pathFormatters, err := c.makePathFormatters([]string{pathA, pathB})
+	assert.NilErr(t, err)
+
+	configMap, err := pathFormatters[pathA].ConfigMap()
+	assert.NilErr(t, err)
+	// Metadata settings apply over overrides.
+	assert.Equal(t, 0, configMap["max_line_length"].(int))
+	// Invalid settings are left out.
+	assert.Equal(t, 4, configMap["indent"].(int))
+	configMap, err = pathFormatters[pathB].ConfigMap()
+	assert.NilErr(t, err)
+	assert.Equal(t, 100, configMap["max_line_length"].(int))
+
+	assert.Equal(t, 2, len(c.metadataErrs))
+	for _, mdErr := range c.metadataErrs {
+		assert.Assert(t, errors.Is(mdErr, yamlfmt.ErrInvalidMetadataSetting), "expected ErrInvalidMetadataSetting, got: %v", mdErr)
+	}
+	assert.Assert(t, strings.Contains(c.metadataErrs[0].Error(), `did you mean "indent"?`), "expected a suggestion, got: %v", c.metadataErrs[0])
+}
+
+func TestMetadataSettingsFromAnalysis(t *testing.T) {
+	tempDir := t.TempDir()
+	files := tempfile.Paths{
+		{BasePath: tempDir, FilePath: "a.yaml", Content: []byte("# !yamlfmt!:set:indent=4\na: 1\n")},
+	}
+	assert.NilErr(t, files.CreateAll())
+	path := filepath.Join(tempDir, "a.yaml")
+
+	c := &Command{
+		Config: &Config{
+			FormatterConfig: &FormatterConfig{FormatterSettings: map[string]any{}},
+		},
+		Registry: yamlfmt.NewFormatterRegistry(&basic.BasicFormatterFactory{}),
+	}
+	paths, _, err := c.analyzePaths([]string{path}, nil)
+	assert.NilErr(t, err)
+	// The settings come from the content analysis, not from reading the
+	// file again.
+	assert.NilErr(t, os.Remove(path))
+	pathFormatters, err := c.makePathFormatters(paths)
+	assert.NilErr(t, err)
+	formatter, ok := pathFormatters[path]
+	assert.Assert(t, ok, "expected metadata settings to apply to %s", path)
+	configMap, err := formatter.ConfigMap()
+	assert.NilErr(t, err)
+	assert.Equal(t, 4, configMap["indent"].(int))
+}
+
+func TestValidateConfigData(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, ".yamlfmt")
+	files := tempfile.Paths{
+		{BasePath: tempDir, FilePath: ".yamlfmt", Content: []byte(`line_ending: lf
+exclud:
+  - vendor
+formatter:
+  indent: 2
+  retain_line_break: true
+`)},
+	}
+	assert.NilErr(t, files.CreateAll())
+	configData, sources, err := ReadConfigFile(configPath)
+	assert.NilErr(t, err)
+	registry := yamlfmt.NewFormatterRegistry(&basic.BasicFormatterFactory{})
+
+	err = ValidateConfigData(configData, sources, registry)
+	assert.NotNilErr(t, err)
+	for _, expected := range []string{
+		configPath + `:2: unknown key "exclud", did you mean "exclude"?`,
+		configPath + `:6: unknown key "formatter.retain_line_break", did you mean "retain_line_breaks"?`,
+	} {
+		assert.Assert(t, strings.Contains(err.Error(), expected), "expected error to contain %q, got: %v", expected, err)
+	}
+
+	configData["disable_strict_config"] = true
+	assert.NilErr(t, ValidateConfigData(configData, sources, registry))
+}
+
+func TestValidateConfigDataValues(t *testing.T) {
+	configData, sources, err := SetConfigData([]string{
+		"formatter.indent=[2]",
+		"formatter.force_array_style=flow",
+		"formatter.max_line_length=abc",
+	}, "flag -formatter")
+	assert.NilErr(t, err)
+	registry := yamlfmt.NewFormatterRegistry(&basic.BasicFormatterFactory{})
+
+	err = ValidateConfigData(configData, sources, registry)
+	assert.NotNilErr(t, err)
+	for _, expected := range []string{
+		`flag -formatter: invalid value for "formatter.indent"`,
+		`flag -formatter: invalid value for "formatter.max_line_length"`,
+	} {
+		assert.Assert(t, strings.Contains(err.Error(), expected), "expected error to contain %q, got: %v", expected, err)
+	}
+	assert.Assert(t, !strings.Contains(err.Error(), "force_array_style"), "expected force_array_style to be valid, got: %v", err)
+
+	// Values are checked even when unknown keys are allowed.
+	configData["disable_strict_config"] = true
+	err = ValidateConfigData(configData, sources, registry)
+	assert.NotNilErr(t, err)
+	assert.Assert(t, strings.Contains(err.Error(), `invalid value for "formatter.indent"`), "expected an invalid value error, got: %v", err)
+}
+
+func TestExplain(t *testing.T) {
+	tempDir := t.TempDir()
+	files := tempfile.Paths{
+		{BasePath: tempDir, FilePath: "a.yaml", Content: []byte("a: 1\n")},
+		{BasePath: tempDir, FilePath: "b.yaml", Content: []byte("# !yamlfmt!:ignore\nb: 1\n")},
+		{BasePath: tempDir, FilePath: "c.yaml", Content: []byte("c: 1\n")},
+	}
+	assert.NilErr(t, files.CreateAll())
+
+	c := &Command{
+		Config: &Config{
+			Include:    []string{tempDir},
+			Exclude:    []string{filepath.Join(tempDir, "c.yaml")},
+			Extensions: []string{"yaml"},
+			LineEnding: "lf",
+			FormatterConfig: &FormatterConfig{
+				Type:              basic.BasicFormatterType,
+				FormatterSettings: map[string]any{"indent": 4},
+			},
+			Overrides: []*OverrideConfig{
+				{
+					Include: []string{filepath.Join(tempDir, "a.yaml")},
+					FormatterConfig: &FormatterConfig{
+						FormatterSettings: map[string]any{"indent": 6},
+					},
+				},
+			},
+		},
+		Registry: yamlfmt.NewFormatterRegistry(&basic.BasicFormatterFactory{}),
+	}
+
+	testCases := []struct {
+		path     string
+		expected []string
+	}{
+		{
+			path:     "a.yaml",
+			expected: []string{"result: formatted", "overrides[0]", "indent: 6"},
+		},
+		{
+			path:     "b.yaml",
+			expected: []string{"!yamlfmt!:ignore metadata on line 1", "result: not formatted", "indent: 4"},
+		},
+		{
+			path:     "c.yaml",
+			expected: []string{"matched by exclude", "result: not formatted"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			var out strings.Builder
+			assert.NilErr(t, c.explain(&out, filepath.Join(tempDir, tc.path)))
+			for _, expected := range tc.expected {
+				assert.Assert(t, strings.Contains(out.String(), expected), "expected %q in output:\n%s", expected, out.String())
+			}
+		})
+	}
+}
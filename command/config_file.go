@@ -0,0 +1,201 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/google/yamlfmt/internal/logger"
+	"github.com/google/yamlfmt/pkg/yaml"
+)
+
+// ConfigFileNames are the names of the files that are recognized as
+// yamlfmt config files during discovery, in order of priority.
+var ConfigFileNames = []string{
+	".yamlfmt",
+	"yamlfmt.yml",
+	"yamlfmt.yaml",
+	".yamlfmt.yaml",
+	".yamlfmt.yml",
+}
+
+const extendsKey = "extends"
+
+var (
+	ErrExtendsCycle   = errors.New("config extends cycle")
+	ErrExtendsInvalid = errors.New("extends must be a path or a list of paths")
+)
+
+// ConfigSources maps the dotted key of each config value, such as
+// `formatter.indent`, to a description of where the value came from.
+type ConfigSources map[string]string
+
+// Remove removes the sources of key and of any keys nested under it.
+func (s ConfigSources) Remove(key string) {
+	for k := range s {
+		if k == key || strings.HasPrefix(k, key+".") {
+			delete(s, k)
+		}
+	}
+}
+
+// Distinct returns the number of different sources.
+func (s ConfigSources) Distinct() int {
+	sources := map[string]struct{}{}
+	for _, source := range s {
+		sources[source] = struct{}{}
+	}
+	return len(sources)
+}
+
+// FindConfigFileInDir returns the path to the highest priority config
+// file in dir, or an empty string if there isn't one.
+func FindConfigFileInDir(dir string) string {
+	for _, filename := range ConfigFileNames {
+		configPath := filepath.Join(dir, filename)
+		if info, err := os.Stat(configPath); err == nil && !info.IsDir() {
+			return configPath
+		}
+	}
+	return ""
+}
+
+// ReadConfigFile reads the yaml config file at path into a map. If the
+// file has an `extends` key, the files it lists are read first and the
+// settings of the file are deep merged over them. The returned sources
+// record which file each value came from.
+func ReadConfigFile(path string) (map[string]any, ConfigSources, error) {
+	return readConfigFile(path, nil)
+}
+
+func readConfigFile(path string, chain []string) (map[string]any, ConfigSources, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if slices.Contains(chain, absPath) {
+		return nil, nil, fmt.Errorf("%w: %s -> %s", ErrExtendsCycle, strings.Join(chain, " -> "), absPath)
+	}
+	chain = append(chain, absPath)
+
+	yamlBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var configData map[string]any
+	err = yaml.Unmarshal(yamlBytes, &configData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if configData == nil {
+		configData = map[string]any{}
+	}
+
+	extends, err := extendsPaths(configData[extendsKey])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	delete(configData, extendsKey)
+
+	merged := map[string]any{}
+	sources := ConfigSources{}
+	for _, extendsPath := range extends {
+		if !filepath.IsAbs(extendsPath) {
+			extendsPath = filepath.Join(filepath.Dir(path), extendsPath)
+		}
+		logger.Debug(logger.DebugCodeConfig, "%s extends %s", path, extendsPath)
+		extendedData, extendedSources, err := readConfigFile(extendsPath, chain)
+		if err != nil {
+			return nil, nil, err
+		}
+		mergeConfigData(merged, extendedData, "", sources, extendedSources)
+	}
+	mergeConfigData(merged, configData, "", sources, sourcesForData(configData, "", displayPath(absPath)))
+	return merged, sources, nil
+}
+
+// displayPath makes absPath relative to the working directory if it is
+// inside of it, since that is easier to read.
+func displayPath(absPath string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return absPath
+	}
+	relPath, err := filepath.Rel(wd, absPath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return absPath
+	}
+	return relPath
+}
+
+func extendsPaths(value any) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []any:
+		paths := []string{}
+		for _, el := range v {
+			path, ok := el.(string)
+			if !ok {
+				return nil, ErrExtendsInvalid
+			}
+			paths = append(paths, path)
+		}
+		return paths, nil
+	}
+	return nil, ErrExtendsInvalid
+}
+
+// mergeConfigData deep merges src into dst. Maps are merged key by key,
+// and any other value in src replaces the value in dst.
+func mergeConfigData(dst map[string]any, src map[string]any, prefix string, dstSources ConfigSources, srcSources ConfigSources) {
+	for key, srcValue := range src {
+		dottedKey := prefix + key
+		srcMap, srcIsMap := srcValue.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+		if srcIsMap && dstIsMap {
+			mergeConfigData(dstMap, srcMap, dottedKey+".", dstSources, srcSources)
+			continue
+		}
+		dst[key] = srcValue
+		dstSources.Remove(dottedKey)
+		for k, source := range srcSources {
+			if k == dottedKey || strings.HasPrefix(k, dottedKey+".") {
+				dstSources[k] = source
+			}
+		}
+	}
+}
+
+func sourcesForData(data map[string]any, prefix string, source string) ConfigSources {
+	sources := ConfigSources{}
+	for key, value := range data {
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			for k, v := range sourcesForData(nested, prefix+key+".", source) {
+				sources[k] = v
+			}
+			continue
+		}
+		sources[prefix+key] = source
+	}
+	return sources
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/yamlfmt/internal/assert"
+	"github.com/google/yamlfmt/internal/tempfile"
+)
+
+func TestReadConfigFileExtends(t *testing.T) {
+	tempDir := t.TempDir()
+	presetDir := t.TempDir()
+	presetPath := filepath.Join(presetDir, "preset.yaml")
+	files := tempfile.Paths{
+		{BasePath: presetDir, FilePath: "preset.yaml", Content: []byte(`line_ending: crlf
+exclude:
+  - vendor
+formatter:
+  indent: 4
+  retain_line_breaks: true
+`)},
+		{BasePath: tempDir, FilePath: "team.yaml", Content: []byte(`extends: ` + presetPath + `
+formatter:
+  indent: 3
+`)},
+		{BasePath: tempDir, FilePath: ".yamlfmt", Content: []byte(`extends:
+  - team.yaml
+exclude:
+  - generated
+formatter:
+  include_document_start: true
+`)},
+	}
+	assert.NilErr(t, files.CreateAll())
+
+	configData, sources, err := ReadConfigFile(filepath.Join(tempDir, ".yamlfmt"))
+	assert.NilErr(t, err)
+
+	assert.Equal(t, "crlf", configData["line_ending"].(string))
+	exclude := configData["exclude"].([]any)
+	assert.Equal(t, 1, len(exclude))
+	assert.Equal(t, "generated", exclude[0].(string))
+	formatter := configData["formatter"].(map[string]any)
+	assert.Equal(t, 3, formatter["indent"].(int))
+	assert.Equal(t, true, formatter["retain_line_breaks"].(bool))
+	assert.Equal(t, true, formatter["include_document_start"].(bool))
+	_, hasExtends := configData["extends"]
+	assert.Assert(t, !hasExtends, "expected extends to be removed from the config data")
+
+	assert.Equal(t, presetPath, sources["line_ending"])
+	assert.Equal(t, presetPath, sources["formatter.retain_line_breaks"])
+	assert.Equal(t, filepath.Join(tempDir, "team.yaml"), sources["formatter.indent"])
+	assert.Equal(t, filepath.Join(tempDir, ".yamlfmt"), sources["exclude"])
+	assert.Equal(t, 3, sources.Distinct())
+}
+
+func TestReadConfigFileExtendsCycle(t *testing.T) {
+	tempDir := t.TempDir()
+	files := tempfile.Paths{
+		{BasePath: tempDir, FilePath: "a.yaml", Content: []byte("extends: b.yaml\n")},
+		{BasePath: tempDir, FilePath: "b.yaml", Content: []byte("extends: a.yaml\n")},
+	}
+	assert.NilErr(t, files.CreateAll())
+
+	_, _, err := ReadConfigFile(filepath.Join(tempDir, "a.yaml"))
+	assert.Assert(t, errors.Is(err, ErrExtendsCycle), "expected an extends cycle error, got: %v", err)
+}
@@ -0,0 +1,159 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/google/yamlfmt/internal/logger"
+	"github.com/google/yamlfmt/pkg/yaml"
+)
+
+// EnvPrefix is the prefix of the environment variables that set config
+// keys, such as YAMLFMT_GITIGNORE_EXCLUDES or YAMLFMT_FORMATTER_INDENT.
+const EnvPrefix = "YAMLFMT_"
+
+var (
+	ErrBadConfigSetting = errors.New("config settings must be in the form key=value")
+	ErrEnvStructuredKey = errors.New("can't be set from the environment, set its keys one at a time or use the config file")
+)
+
+// EnvConfigData reads the config keys set by the YAMLFMT_<KEY> variables
+// in environ, which is in the form returned by os.Environ. Variables that
+// don't name a top level key or a `formatter` key are ignored. Keys whose
+// value is a map or a list of maps, such as `formatter` and `overrides`,
+// can't be set with a single variable and are an error.
+func EnvConfigData(environ []string) (map[string]any, ConfigSources, error) {
+	data := map[string]any{}
+	sources := ConfigSources{}
+	topLevel := mapstructureKeys(reflect.TypeFor[Config]())
+	for _, env := range environ {
+		name, value, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+		key := strings.ToLower(strings.TrimPrefix(name, EnvPrefix))
+		if isStructuredKey(key) {
+			return nil, nil, fmt.Errorf("%s: %q %w", name, key, ErrEnvStructuredKey)
+		}
+		if !slices.Contains(topLevel, key) {
+			formatterKey, ok := strings.CutPrefix(key, "formatter_")
+			if !ok {
+				logger.Debug(logger.DebugCodeConfig, "ignoring %s, it isn't a config key", name)
+				continue
+			}
+			key = "formatter." + formatterKey
+		}
+		setConfigKey(data, key, value)
+		sources[key] = "env " + name
+	}
+	return data, sources, nil
+}
+
+// SetConfigData reads config keys from settings in the form key=value.
+// Nested keys are separated by dots, such as `formatter.indent=4`. Values
+// are parsed as yaml, and a list key can also be given a comma separated
+// list.
+func SetConfigData(settings []string, source string) (map[string]any, ConfigSources, error) {
+	data := map[string]any{}
+	sources := ConfigSources{}
+	for _, setting := range settings {
+		key, value, ok := strings.Cut(setting, "=")
+		if !ok || key == "" {
+			return nil, nil, fmt.Errorf("%w: %s", ErrBadConfigSetting, setting)
+		}
+		setConfigKey(data, key, value)
+		sources.Remove(key)
+		sources[key] = source
+	}
+	return data, sources, nil
+}
+
+// MergeConfigData deep merges the config data in src over dst, and
+// updates dstSources to record where the merged values came from.
+func MergeConfigData(dst map[string]any, dstSources ConfigSources, src map[string]any, srcSources ConfigSources) {
+	mergeConfigData(dst, src, "", dstSources, srcSources)
+}
+
+func setConfigKey(data map[string]any, key string, rawValue string) {
+	segments := strings.Split(key, ".")
+	for _, segment := range segments[:len(segments)-1] {
+		nested, ok := data[segment].(map[string]any)
+		if !ok {
+			nested = map[string]any{}
+			data[segment] = nested
+		}
+		data = nested
+	}
+	data[segments[len(segments)-1]] = parseConfigValue(key, rawValue)
+}
+
+// parseConfigValue parses rawValue as yaml, so that numbers, booleans and
+// flow style lists are typed the same way they would be in a config file.
+// Values that aren't valid yaml, like globs starting with `*`, are kept
+// as strings.
+func parseConfigValue(key string, rawValue string) any {
+	var value any
+	if err := yaml.Unmarshal([]byte(rawValue), &value); err != nil || value == nil {
+		value = rawValue
+	}
+	if s, ok := value.(string); ok && isListKey(key) {
+		values := []any{}
+		if s == "" {
+			return values
+		}
+		for _, el := range strings.Split(s, ",") {
+			values = append(values, strings.TrimSpace(el))
+		}
+		return values
+	}
+	return value
+}
+
+func isListKey(key string) bool {
+	fieldType, ok := configFieldType(key)
+	return ok && fieldType.Kind() == reflect.Slice
+}
+
+// isStructuredKey reports whether the value of the top level key is a map
+// or a list of maps, rather than a scalar or a list of scalars.
+func isStructuredKey(key string) bool {
+	fieldType, ok := configFieldType(key)
+	if !ok {
+		return false
+	}
+	if fieldType.Kind() == reflect.Slice {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType.Kind() == reflect.Struct || fieldType.Kind() == reflect.Map
+}
+
+func configFieldType(key string) (reflect.Type, bool) {
+	t := reflect.TypeFor[Config]()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("mapstructure"), ",")
+		if name == key {
+			return t.Field(i).Type, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/yamlfmt/internal/assert"
+)
+
+func TestConfigLayers(t *testing.T) {
+	configData := map[string]any{
+		"gitignore_excludes": true,
+		"exclude":            []any{"vendor"},
+		"formatter":          map[string]any{"indent": 4, "retain_line_breaks": true},
+	}
+	sources := ConfigSources{
+		"gitignore_excludes":           ".yamlfmt",
+		"exclude":                      ".yamlfmt",
+		"formatter.indent":             ".yamlfmt",
+		"formatter.retain_line_breaks": ".yamlfmt",
+	}
+
+	envData, envSources, err := EnvConfigData([]string{
+		"HOME=/home/user",
+		"YAMLFMT_GITIGNORE_EXCLUDES=false",
+		"YAMLFMT_FORMATTER_INDENT=6",
+		"YAMLFMT_EXTENSIONS=yaml,yml,tpl",
+		"YAMLFMT_UNRELATED=1",
+	})
+	assert.NilErr(t, err)
+	assert.Equal(t, 3, len(envSources))
+	MergeConfigData(configData, sources, envData, envSources)
+
+	setData, setSources, err := SetConfigData([]string{
+		"formatter.indent=8",
+		"exclude=**/gen/*.yaml",
+		"include=[a, b]",
+	}, "flag -set")
+	assert.NilErr(t, err)
+	MergeConfigData(configData, sources, setData, setSources)
+
+	assert.Equal(t, false, configData["gitignore_excludes"].(bool))
+	assert.Equal(t, "env YAMLFMT_GITIGNORE_EXCLUDES", sources["gitignore_excludes"])
+	assert.Equal(t, 3, len(configData["extensions"].([]any)))
+	exclude := configData["exclude"].([]any)
+	assert.Equal(t, 1, len(exclude))
+	assert.Equal(t, "**/gen/*.yaml", exclude[0].(string))
+	assert.Equal(t, 2, len(configData["include"].([]any)))
+
+	formatter := configData["formatter"].(map[string]any)
+	assert.Equal(t, 8, formatter["indent"].(int))
+	assert.Equal(t, "flag -set", sources["formatter.indent"])
+	assert.Equal(t, true, formatter["retain_line_breaks"].(bool))
+	assert.Equal(t, ".yamlfmt", sources["formatter.retain_line_breaks"])
+}
+
+func TestEnvConfigDataStructuredKey(t *testing.T) {
+	for _, env := range []string{"YAMLFMT_FORMATTER=foo", "YAMLFMT_OVERRIDES=[]"} {
+		_, _, err := EnvConfigData([]string{env})
+		assert.Assert(t, errors.Is(err, ErrEnvStructuredKey), "expected ErrEnvStructuredKey for %s, got %v", env, err)
+		name, _, _ := strings.Cut(env, "=")
+		assert.Assert(t, strings.Contains(err.Error(), name), "expected the error to name %s, got %v", name, err)
+	}
+}
+
+func TestSetConfigDataBadSetting(t *testing.T) {
+	_, _, err := SetConfigData([]string{"indent"}, "flag -set")
+	assert.Assert(t, errors.Is(err, ErrBadConfigSetting), "expected ErrBadConfigSetting, got %v", err)
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"strconv"
+
+	"github.com/google/yamlfmt"
+	"github.com/google/yamlfmt/formatters/basic"
+	"github.com/google/yamlfmt/internal/editorconfig"
+	"github.com/google/yamlfmt/internal/logger"
+)
+
+// editorConfigSettings maps the EditorConfig properties that have an
+// equivalent in the basic formatter onto basic formatter settings.
+// Values that the basic formatter can't represent, such as tab
+// indentation or `cr` line endings, are ignored.
+func editorConfigSettings(props editorconfig.Properties) map[string]any {
+	settings := map[string]any{}
+	if value, ok := props["indent_size"]; ok {
+		if indent, err := strconv.Atoi(value); err == nil && indent > 0 {
+			settings["indent"] = indent
+		}
+	}
+	switch props["end_of_line"] {
+	case "lf":
+		settings["line_ending"] = yamlfmt.LineBreakStyleLF
+	case "crlf":
+		settings["line_ending"] = yamlfmt.LineBreakStyleCRLF
+	}
+	if value, ok := props["insert_final_newline"]; ok {
+		if eofNewline, err := strconv.ParseBool(value); err == nil {
+			settings["eof_newline"] = eofNewline
+		}
+	}
+	if value, ok := props["trim_trailing_whitespace"]; ok {
+		if trim, err := strconv.ParseBool(value); err == nil {
+			settings["trim_trailing_whitespace"] = trim
+		}
+	}
+	if value, ok := props["max_line_length"]; ok {
+		if value == "off" {
+			settings["max_line_length"] = 0
+		} else if lineLength, err := strconv.Atoi(value); err == nil && lineLength > 0 {
+			settings["max_line_length"] = lineLength
+		}
+	}
+	return settings
+}
+
+// applyEditorConfig fills in the basic formatter settings for path from
+// `.editorconfig` files. Settings in fc always take precedence, as does a
+// line ending set at the top level of the config file. It returns nil if
+// EditorConfig doesn't change anything.
+func (c *Command) applyEditorConfig(fc *FormatterConfig, path string) (*FormatterConfig, error) {
+	if fc.Type != basic.BasicFormatterType {
+		return nil, nil
+	}
+	if c.editorConfig == nil {
+		c.editorConfig = editorconfig.NewResolver()
+	}
+	props, err := c.editorConfig.Properties(path)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := editorConfigSettings(props)
+	for key := range settings {
+		if _, ok := fc.FormatterSettings[key]; ok {
+			delete(settings, key)
+		}
+	}
+	if _, ok := c.ConfigSources["line_ending"]; ok {
+		delete(settings, "line_ending")
+	}
+	if len(settings) == 0 {
+		return nil, nil
+	}
+	logger.Debug(logger.DebugCodeConfig, "editorconfig settings %v apply to %s", settings, path)
+	return fc.Merge(&FormatterConfig{FormatterSettings: settings}), nil
+}
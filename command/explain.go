@@ -0,0 +1,145 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/yamlfmt"
+	"github.com/google/yamlfmt/pkg/yaml"
+)
+
+// explain writes each step that decides whether path is formatted, and
+// the formatter settings that apply to it.
+func (c *Command) explain(w io.Writer, path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	formatted := true
+	fmt.Fprintf(w, "path: %s\n", path)
+	if c.ConfigPath != "" {
+		fmt.Fprintf(w, "config file: %s\n", displayPath(c.ConfigPath))
+	} else {
+		fmt.Fprintln(w, "config file: none, using defaults")
+	}
+
+	collector, err := c.makePathCollector()
+	if err != nil {
+		return err
+	}
+	if explainer, ok := collector.(yamlfmt.PathExplainer); ok {
+		collected, reason, err := explainer.ExplainPath(path)
+		if err != nil {
+			return err
+		}
+		formatted = formatted && collected
+		fmt.Fprintf(w, "paths: %s\n", reason)
+	} else {
+		fmt.Fprintf(w, "paths: match type %s can't explain paths\n", c.Config.MatchType)
+	}
+
+	if c.Config.GitignoreExcludes {
+		excluded, reason, err := yamlfmt.ExplainGitignore(c.Config.GitignorePath, path)
+		if err != nil {
+			return err
+		}
+		formatted = formatted && !excluded
+		fmt.Fprintf(w, "gitignore: %s\n", reason)
+	}
+
+	excluded, reason, err := yamlfmt.ExplainYamlfmtIgnore(path)
+	if err != nil {
+		return err
+	}
+	formatted = formatted && !excluded
+	fmt.Fprintf(w, "yamlfmtignore: %s\n", reason)
+
+	if c.Config.ChangedSince != "" {
+		changed, reason, err := yamlfmt.ExplainChanged(c.Config.ChangedSince, path)
+		if err != nil {
+			return err
+		}
+		formatted = formatted && changed
+		fmt.Fprintf(w, "changed: %s\n", reason)
+	}
+
+	analyzer, err := c.makeAnalyzer()
+	if err != nil {
+		return err
+	}
+	if explainer, ok := analyzer.(yamlfmt.ContentExplainer); ok {
+		reason, err := explainer.ExcludeReason(path)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			formatted = false
+			fmt.Fprintf(w, "content: excluded by %s\n", reason)
+		} else {
+			fmt.Fprintln(w, "content: not excluded")
+		}
+	}
+
+	frontMatter := yamlfmt.ExtensionMatches(path, c.Config.FrontMatterExtensions)
+	codeBlocks := yamlfmt.ExtensionMatches(path, c.Config.CodeBlockExtensions)
+	switch {
+	case formatted && frontMatter && codeBlocks:
+		fmt.Fprintln(w, "result: formatted, front matter and yaml code blocks only")
+	case formatted && frontMatter:
+		fmt.Fprintln(w, "result: formatted, front matter only")
+	case formatted && codeBlocks:
+		fmt.Fprintln(w, "result: formatted, yaml code blocks only")
+	case formatted:
+		fmt.Fprintln(w, "result: formatted")
+	default:
+		fmt.Fprintln(w, "result: not formatted")
+	}
+
+	if err := c.normalizeFormatterType(); err != nil {
+		return err
+	}
+	fc, layers, err := c.formatterConfigForPath(path)
+	if err != nil {
+		return err
+	}
+	formatter, err := c.newFormatter(fc)
+	if err != nil {
+		return err
+	}
+	configMap, err := formatter.ConfigMap()
+	if err != nil {
+		return err
+	}
+	out, err := yaml.Marshal(configMap)
+	if err != nil {
+		return err
+	}
+	if len(layers) == 0 {
+		fmt.Fprintln(w, "formatter settings:")
+	} else {
+		fmt.Fprintf(w, "formatter settings (with %s):\n", strings.Join(layers, ", "))
+	}
+	for _, line := range strings.SplitAfter(strings.TrimSuffix(string(out), "\n"), "\n") {
+		fmt.Fprintf(w, "    %s", line)
+	}
+	fmt.Fprintln(w)
+	for _, mdErr := range c.metadataErrs {
+		fmt.Fprintln(w, mdErr)
+	}
+	return nil
+}
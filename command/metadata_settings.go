@@ -0,0 +1,95 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/google/yamlfmt"
+	"github.com/google/yamlfmt/internal/collections"
+	"github.com/google/yamlfmt/internal/logger"
+	"github.com/mitchellh/mapstructure"
+)
+
+// applyMetadataSettings layers the settings from `!yamlfmt!:set` metadata
+// in the file at path over fc. Invalid settings are left out and returned
+// as metadata errors, which don't stop the file from being formatted. It
+// returns a nil config if the file sets nothing.
+func (c *Command) applyMetadataSettings(fc *FormatterConfig, path string) (*FormatterConfig, collections.Errors, error) {
+	setMetadata, ok := c.setMetadata[path]
+	if !ok {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			// The file can't be formatted either, which reports the error.
+			return nil, nil, nil
+		}
+		// Errors in the rest of the metadata are reported by the content
+		// analysis.
+		metadata, _ := yamlfmt.ReadMetadata(content, path)
+		setMetadata = yamlfmt.SetMetadata(metadata)
+	}
+	if len(setMetadata) == 0 {
+		return nil, nil, nil
+	}
+	factory, err := c.Registry.GetFactory(fc.Type)
+	if err != nil {
+		return nil, nil, err
+	}
+	knownKeys, err := formatterKeys(factory)
+	if err != nil {
+		return nil, nil, err
+	}
+	settings, mdErrs := yamlfmt.CheckMetadataSettings(setMetadata, path, func(key string, value any) error {
+		// A file can't switch to another formatter, since the rest of its
+		// settings belong to this one.
+		if key == "type" || !slices.Contains(knownKeys, key) {
+			msg := fmt.Sprintf("%s formatter has no setting %q", factory.Type(), key)
+			if suggestion := suggestKey(key, knownKeys); suggestion != "" && suggestion != "type" {
+				msg += fmt.Sprintf(", did you mean %q?", suggestion)
+			}
+			return errors.New(msg)
+		}
+		if _, err := factory.NewFormatter(map[string]any{key: value}); err != nil {
+			var decodeErr *mapstructure.Error
+			if errors.As(err, &decodeErr) && len(decodeErr.Errors) == 1 {
+				err = errors.New(decodeErr.Errors[0])
+			}
+			return err
+		}
+		return nil
+	})
+	if len(settings) == 0 {
+		return nil, mdErrs, nil
+	}
+	logger.Debug(logger.DebugCodeConfig, "metadata settings %v apply to %s", settings, path)
+	return fc.Merge(&FormatterConfig{FormatterSettings: settings}), mdErrs, nil
+}
+
+// recordSetMetadata is a ContentCheck that keeps the `!yamlfmt!:set`
+// metadata of each file for applyMetadataSettings. It never excludes a
+// file.
+func (c *Command) recordSetMetadata(file *yamlfmt.AnalyzedFile) (string, error) {
+	content, err := file.Content()
+	if err != nil {
+		return "", nil
+	}
+	// Errors in the metadata are reported by the basic content analyzer.
+	metadata, _ := yamlfmt.ReadMetadata(content, file.Path)
+	c.setMetadata[file.Path] = yamlfmt.SetMetadata(metadata)
+	return "", nil
+}
@@ -0,0 +1,225 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/yamlfmt"
+	"github.com/google/yamlfmt/internal/collections"
+	"github.com/google/yamlfmt/internal/logger"
+	"github.com/mitchellh/mapstructure"
+)
+
+var ErrRootOnlyConfigKeys = errors.New("nested config has keys that only apply to the root config (set disable_strict_config: true to ignore them)")
+
+// nestedConfigKeys are the top level keys that are used from a nested
+// config file. Everything else only applies to the root config.
+var nestedConfigKeys = []string{"formatter", "line_ending", "inherit", "disable_strict_config"}
+
+type resolvedNestedConfig struct {
+	configPath      string
+	formatterConfig *FormatterConfig
+	// Whether the chain of inheriting configs reaches the root config.
+	inheritsRoot bool
+}
+
+// nestedConfigResolver finds the config file closest to each path
+// below the root config, and resolves the formatter config it
+// describes including any configs it inherits from.
+type nestedConfigResolver struct {
+	base        *FormatterConfig
+	defaultType string
+	stopDirs    []string
+	registry    *yamlfmt.Registry
+	strict      bool
+
+	configForDir map[string]string
+	resolved     map[string]resolvedNestedConfig
+}
+
+func (c *Command) newNestedConfigResolver() (*nestedConfigResolver, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	stopDirs := []string{wd}
+	if c.ConfigPath != "" {
+		rootConfigPath, err := filepath.Abs(c.ConfigPath)
+		if err != nil {
+			return nil, err
+		}
+		stopDirs = append(stopDirs, filepath.Dir(rootConfigPath))
+	}
+	return &nestedConfigResolver{
+		base:         c.Config.FormatterConfig,
+		defaultType:  c.Config.FormatterConfig.Type,
+		stopDirs:     stopDirs,
+		registry:     c.Registry,
+		strict:       !c.Config.DisableStrictConfig,
+		configForDir: map[string]string{},
+		resolved:     map[string]resolvedNestedConfig{},
+	}, nil
+}
+
+// formatterConfigForPath returns the resolved formatter config of the
+// nearest nested config for path, or nil if the root config applies.
+func (r *nestedConfigResolver) formatterConfigForPath(path string) (*resolvedNestedConfig, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	// Nested configs are only searched for below a stop dir, so that a
+	// config file in an unrelated directory, such as the home directory,
+	// never applies to a path outside of the project.
+	if !r.isUnderStopDir(absPath) {
+		return nil, nil
+	}
+	configPath := r.nearestConfig(filepath.Dir(absPath))
+	if configPath == "" {
+		return nil, nil
+	}
+	logger.Debug(logger.DebugCodeConfig, "nested config %s applies to %s", configPath, path)
+	resolved, err := r.resolve(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return &resolved, nil
+}
+
+// nearestConfig searches from dir up the tree for a config file,
+// stopping before the working directory or the root config's directory.
+func (r *nestedConfigResolver) nearestConfig(dir string) string {
+	if configPath, ok := r.configForDir[dir]; ok {
+		return configPath
+	}
+	configPath := ""
+	parent := filepath.Dir(dir)
+	if !r.isStopDir(dir) && parent != dir {
+		configPath = FindConfigFileInDir(dir)
+		if configPath == "" {
+			configPath = r.nearestConfig(parent)
+		}
+	}
+	r.configForDir[dir] = configPath
+	return configPath
+}
+
+func (r *nestedConfigResolver) isStopDir(dir string) bool {
+	for _, stopDir := range r.stopDirs {
+		if dir == stopDir {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnderStopDir reports whether path is within one of the stop dirs.
+func (r *nestedConfigResolver) isUnderStopDir(path string) bool {
+	for _, stopDir := range r.stopDirs {
+		relPath, err := filepath.Rel(stopDir, path)
+		if err == nil && relPath != ".." && !strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *nestedConfigResolver) resolve(configPath string) (resolvedNestedConfig, error) {
+	if resolved, ok := r.resolved[configPath]; ok {
+		return resolved, nil
+	}
+	configData, sources, err := ReadConfigFile(configPath)
+	if err != nil {
+		return resolvedNestedConfig{}, err
+	}
+	if r.strict {
+		if err := ValidateConfigData(configData, sources, r.registry); err != nil {
+			return resolvedNestedConfig{}, err
+		}
+		if err := validateNestedConfigKeys(configData, sources); err != nil {
+			return resolvedNestedConfig{}, err
+		}
+	} else if err := ValidateConfigValues(configData, sources, r.registry); err != nil {
+		return resolvedNestedConfig{}, err
+	}
+	config := Config{FormatterConfig: NewFormatterConfig()}
+	if err := mapstructure.Decode(configData, &config); err != nil {
+		return resolvedNestedConfig{}, fmt.Errorf("%s: %w", configPath, err)
+	}
+	if config.FormatterConfig.Type == "" {
+		config.FormatterConfig.Type = r.defaultType
+	}
+	if _, ok := config.FormatterConfig.FormatterSettings["line_ending"]; !ok && config.LineEnding != "" {
+		config.FormatterConfig.FormatterSettings["line_ending"] = config.LineEnding
+	}
+
+	resolved := resolvedNestedConfig{configPath: displayPath(configPath)}
+	if !config.Inherit {
+		resolved.formatterConfig = NewFormatterConfig().Merge(config.FormatterConfig)
+	} else {
+		parentConfigPath := r.nearestConfig(filepath.Dir(filepath.Dir(configPath)))
+		if parentConfigPath == "" {
+			resolved.formatterConfig = r.base.Merge(config.FormatterConfig)
+			resolved.inheritsRoot = true
+		} else {
+			parent, err := r.resolve(parentConfigPath)
+			if err != nil {
+				return resolvedNestedConfig{}, err
+			}
+			resolved.formatterConfig = parent.formatterConfig.Merge(config.FormatterConfig)
+			resolved.inheritsRoot = parent.inheritsRoot
+		}
+	}
+	r.resolved[configPath] = resolved
+	return resolved, nil
+}
+
+// validateNestedConfigKeys reports the keys of a nested config file that
+// only apply to the root config, which would otherwise be ignored without
+// a word. Like unknown keys, they are allowed with `disable_strict_config`.
+func validateNestedConfigKeys(configData map[string]any, sources ConfigSources) error {
+	if disabled, ok := configData["disable_strict_config"].(bool); ok && disabled {
+		return nil
+	}
+	keys := []string{}
+	for key := range configData {
+		if !slices.Contains(nestedConfigKeys, key) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+	errs := collections.Errors{ErrRootOnlyConfigKeys}
+	for _, key := range keys {
+		source := sources.sourceOf(key)
+		if line := keyLine(source, key); line > 0 {
+			source = fmt.Sprintf("%s:%d", source, line)
+		}
+		if source != "" {
+			source += ": "
+		}
+		errs = append(errs, fmt.Errorf("%skey %q only applies to the root config", source, key))
+	}
+	return errs.Combine()
+}
@@ -0,0 +1,266 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/google/yamlfmt"
+	"github.com/google/yamlfmt/internal/collections"
+	"github.com/google/yamlfmt/internal/logger"
+	"github.com/google/yamlfmt/pkg/yaml"
+)
+
+var errOverrideNoInclude = errors.New("override must have at least one include pattern")
+
+// OverrideConfig is a partial formatter config that is merged over the
+// base formatter settings for every path that matches one of the Include
+// patterns and none of the Exclude patterns. Patterns use doublestar syntax
+// and are matched against paths relative to the directory of the config
+// file that declares the override.
+type OverrideConfig struct {
+	Include         []string         `mapstructure:"include" yaml:"include"`
+	Exclude         []string         `mapstructure:"exclude" yaml:"exclude,omitempty"`
+	FormatterConfig *FormatterConfig `mapstructure:"formatter" yaml:"formatter"`
+}
+
+// Matches reports whether the override applies to path. The path and any
+// absolute patterns are made relative to baseDir before matching, where
+// an empty baseDir is the working directory.
+func (o *OverrideConfig) Matches(path string, baseDir string) (bool, error) {
+	if len(o.Include) == 0 {
+		return false, errOverrideNoInclude
+	}
+	absBaseDir, err := filepath.Abs(baseDir)
+	if err != nil {
+		return false, err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	relPath, err := filepath.Rel(absBaseDir, absPath)
+	if err != nil {
+		return false, nil
+	}
+	included, err := matchAny(o.Include, absBaseDir, relPath)
+	if err != nil || !included {
+		return false, err
+	}
+	excluded, err := matchAny(o.Exclude, absBaseDir, relPath)
+	return !excluded, err
+}
+
+func matchAny(patterns []string, absBaseDir string, relPath string) (bool, error) {
+	for _, pattern := range patterns {
+		if filepath.IsAbs(pattern) {
+			relPattern, err := filepath.Rel(absBaseDir, pattern)
+			if err != nil {
+				continue
+			}
+			pattern = relPattern
+		}
+		pattern = filepath.Clean(pattern)
+		// Patterns like `**` would match paths outside of the base
+		// directory too, so only ones that lead out of it can.
+		if isOutsideDir(relPath) && !isOutsideDir(pattern) {
+			continue
+		}
+		match, err := doublestar.PathMatch(pattern, relPath)
+		if err != nil {
+			return false, err
+		}
+		if match {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isOutsideDir reports whether the relative path leads out of the
+// directory it is relative to.
+func isOutsideDir(relPath string) bool {
+	return relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator))
+}
+
+// overridesDir returns the directory that the override patterns are
+// relative to, which is the directory of the config file that set them,
+// or the working directory if they were set from the command line or the
+// environment.
+func (c *Command) overridesDir() string {
+	source := c.ConfigSources.sourceOf("overrides")
+	switch {
+	case source == "":
+		if c.ConfigPath != "" {
+			return filepath.Dir(c.ConfigPath)
+		}
+		return ""
+	case strings.HasPrefix(source, "flag ") || strings.HasPrefix(source, "env "):
+		return ""
+	}
+	return filepath.Dir(source)
+}
+
+// Merge returns a new formatter config with the settings from override
+// layered over fc. If override switches to a different formatter type,
+// none of the settings from fc carry over since they belong to another
+// formatter.
+func (fc *FormatterConfig) Merge(override *FormatterConfig) *FormatterConfig {
+	merged := NewFormatterConfig()
+	merged.Type = fc.Type
+	if override == nil {
+		maps.Copy(merged.FormatterSettings, fc.FormatterSettings)
+		return merged
+	}
+	if override.Type == "" || override.Type == fc.Type {
+		maps.Copy(merged.FormatterSettings, fc.FormatterSettings)
+	} else {
+		merged.Type = override.Type
+	}
+	maps.Copy(merged.FormatterSettings, override.FormatterSettings)
+	return merged
+}
+
+// formatterConfigForPath returns the formatter config that applies to path,
+// along with a description of each layer of config that was applied over
+// the base formatter config. No layers means the base config applies.
+func (c *Command) formatterConfigForPath(path string) (*FormatterConfig, []string, error) {
+	fc := c.Config.FormatterConfig
+	layers := []string{}
+	applyOverrides := true
+	if c.Config.NestedConfigs {
+		if c.nestedConfigs == nil {
+			resolver, err := c.newNestedConfigResolver()
+			if err != nil {
+				return nil, nil, err
+			}
+			c.nestedConfigs = resolver
+		}
+		nested, err := c.nestedConfigs.formatterConfigForPath(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if nested != nil {
+			fc = nested.formatterConfig
+			layers = append(layers, "nested config "+nested.configPath)
+			// The overrides belong to the root config, so they only
+			// apply if the nested config inherits from it.
+			applyOverrides = nested.inheritsRoot
+		}
+	}
+	if applyOverrides {
+		overridesDir := c.overridesDir()
+		for i, override := range c.Config.Overrides {
+			match, err := override.Matches(path, overridesDir)
+			if err != nil {
+				return nil, nil, fmt.Errorf("overrides[%d]: %w", i, err)
+			}
+			if !match {
+				continue
+			}
+			logger.Debug(logger.DebugCodeConfig, "override %d applies to %s", i, path)
+			fc = fc.Merge(override.FormatterConfig)
+			layers = append(layers, fmt.Sprintf("overrides[%d] (include %v)", i, override.Include))
+		}
+	}
+	if c.Config.EditorConfig {
+		editorConfigFC, err := c.applyEditorConfig(fc, path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if editorConfigFC != nil {
+			fc = editorConfigFC
+			layers = append(layers, "editorconfig")
+		}
+	}
+	// Settings in the file itself are the most specific, so they apply
+	// last.
+	metadataFC, mdErrs, err := c.applyMetadataSettings(fc, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.metadataErrs = append(c.metadataErrs, mdErrs...)
+	if metadataFC != nil {
+		fc = metadataFC
+		layers = append(layers, yamlfmt.MetadataIdentifier+":"+string(yamlfmt.MetadataSet)+" metadata")
+	}
+	return fc, layers, nil
+}
+
+// makePathFormatters builds formatters for every path whose formatter config
+// differs from the base one. Paths that share the same effective config
+// share a formatter. Paths not in the result use the default formatter.
+func (c *Command) makePathFormatters(paths []string) (map[string]yamlfmt.Formatter, error) {
+	if err := c.normalizeFormatterType(); err != nil {
+		return nil, err
+	}
+
+	pathFormatters := map[string]yamlfmt.Formatter{}
+	formattersByConfig := map[string]yamlfmt.Formatter{}
+	errs := collections.Errors{}
+	for _, path := range paths {
+		fc, layers, err := c.formatterConfigForPath(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(layers) == 0 {
+			continue
+		}
+		key, err := fc.cacheKey()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		formatter, ok := formattersByConfig[key]
+		if !ok {
+			formatter, err = c.newFormatter(fc)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", path, err))
+				continue
+			}
+			formattersByConfig[key] = formatter
+		}
+		pathFormatters[path] = formatter
+	}
+	return pathFormatters, errs.Combine()
+}
+
+// normalizeFormatterType sets the base formatter type to the default if
+// it's unset, so that merging configs can tell whether an unset type
+// refers to the same formatter.
+func (c *Command) normalizeFormatterType() error {
+	if c.Config.FormatterConfig.Type != "" {
+		return nil
+	}
+	factory, err := c.Registry.GetDefaultFactory()
+	if err != nil {
+		return err
+	}
+	c.Config.FormatterConfig.Type = factory.Type()
+	return nil
+}
+
+// The key is the config marshalled to yaml, which is stable because
+// map keys are sorted when marshalling.
+func (fc *FormatterConfig) cacheKey() (string, error) {
+	out, err := yaml.Marshal(fc)
+	return string(out), err
+}
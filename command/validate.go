@@ -0,0 +1,405 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/yamlfmt"
+	"github.com/google/yamlfmt/internal/collections"
+	"github.com/google/yamlfmt/pkg/yaml"
+	"github.com/mitchellh/mapstructure"
+)
+
+var ErrUnknownConfigKeys = errors.New("config has unknown keys (set disable_strict_config: true to ignore them)")
+
+// UnknownKeyError reports a config key that yamlfmt doesn't recognize.
+type UnknownKeyError struct {
+	Key        string
+	Source     string
+	Line       int
+	Suggestion string
+}
+
+func (e *UnknownKeyError) Error() string {
+	msg := fmt.Sprintf("unknown key %q", e.Key)
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf(", did you mean %q?", e.Suggestion)
+	}
+	switch {
+	case e.Source != "" && e.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", e.Source, e.Line, msg)
+	case e.Source != "":
+		return fmt.Sprintf("%s: %s", e.Source, msg)
+	}
+	return msg
+}
+
+// ValidateConfigData checks the config read from a config file for
+// formatter settings with invalid values, and for keys that don't belong
+// to the top level config or to the formatter that they configure unless
+// `disable_strict_config` is set. Sources are used to point each error at
+// the file and line the key came from.
+func ValidateConfigData(configData map[string]any, sources ConfigSources, registry *yamlfmt.Registry) error {
+	valueErrs := invalidConfigValues(configData, sources, registry)
+	if disabled, ok := configData["disable_strict_config"].(bool); ok && disabled {
+		return valueErrs.Combine()
+	}
+
+	unknownKeys, err := unknownTopLevelKeys(configData)
+	if err != nil {
+		// Decoding errors are reported when the config is used.
+		return valueErrs.Combine()
+	}
+	unknownErrs := []*UnknownKeyError{}
+	for _, key := range unknownKeys {
+		unknownErrs = append(unknownErrs, &UnknownKeyError{
+			Key:        key,
+			Suggestion: suggestKey(lastKeySegment(key), topLevelKeys(key)),
+		})
+	}
+
+	baseType := baseFormatterType(configData, registry)
+	overrides, _ := configData["overrides"].([]any)
+	formatterErrs, err := unknownFormatterKeys("formatter", formatterDataOf(configData), baseType, registry)
+	if err != nil {
+		return err
+	}
+	unknownErrs = append(unknownErrs, formatterErrs...)
+	for i, override := range overrides {
+		overrideData, ok := override.(map[string]any)
+		if !ok {
+			continue
+		}
+		formatterData := formatterDataOf(overrideData)
+		prefix := fmt.Sprintf("overrides[%d].formatter", i)
+		formatterErrs, err := unknownFormatterKeys(prefix, formatterData, overrideFormatterType(formatterData, baseType), registry)
+		if err != nil {
+			return err
+		}
+		unknownErrs = append(unknownErrs, formatterErrs...)
+	}
+
+	if len(unknownErrs) == 0 {
+		return valueErrs.Combine()
+	}
+	for _, unknownErr := range unknownErrs {
+		unknownErr.Source = sources.sourceOf(unknownErr.Key)
+		if unknownErr.Source != "" {
+			unknownErr.Line = keyLine(unknownErr.Source, unknownErr.Key)
+		}
+	}
+	sort.SliceStable(unknownErrs, func(i, j int) bool {
+		if unknownErrs[i].Source != unknownErrs[j].Source {
+			return unknownErrs[i].Source < unknownErrs[j].Source
+		}
+		return unknownErrs[i].Line < unknownErrs[j].Line
+	})
+	errs := collections.Errors{ErrUnknownConfigKeys}
+	for _, unknownErr := range unknownErrs {
+		errs = append(errs, unknownErr)
+	}
+	return errs.Combine()
+}
+
+// ValidateConfigValues checks only the formatter settings in the config
+// for invalid values. Unlike ValidateConfigData, it doesn't depend on
+// strict config, so it is used where unknown keys are allowed.
+func ValidateConfigValues(configData map[string]any, sources ConfigSources, registry *yamlfmt.Registry) error {
+	return invalidConfigValues(configData, sources, registry).Combine()
+}
+
+func invalidConfigValues(configData map[string]any, sources ConfigSources, registry *yamlfmt.Registry) collections.Errors {
+	baseType := baseFormatterType(configData, registry)
+	errs := invalidFormatterValues("formatter", formatterDataOf(configData), baseType, registry, sources)
+	overrides, _ := configData["overrides"].([]any)
+	for i, override := range overrides {
+		overrideData, ok := override.(map[string]any)
+		if !ok {
+			continue
+		}
+		formatterData := formatterDataOf(overrideData)
+		prefix := fmt.Sprintf("overrides[%d].formatter", i)
+		errs = append(errs, invalidFormatterValues(prefix, formatterData, overrideFormatterType(formatterData, baseType), registry, sources)...)
+	}
+	return errs
+}
+
+// baseFormatterType returns the formatter type the config sets, or the
+// type of the default formatter if it doesn't set one.
+func baseFormatterType(configData map[string]any, registry *yamlfmt.Registry) string {
+	if baseType, _ := formatterDataOf(configData)["type"].(string); baseType != "" {
+		return baseType
+	}
+	if factory, err := registry.GetDefaultFactory(); err == nil {
+		return factory.Type()
+	}
+	return ""
+}
+
+func unknownTopLevelKeys(configData map[string]any) ([]string, error) {
+	var metadata mapstructure.Metadata
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Metadata: &metadata,
+		Result:   &Config{FormatterConfig: NewFormatterConfig()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(configData); err != nil {
+		return nil, err
+	}
+	unknownKeys := slices.Clone(metadata.Unused)
+	sort.Strings(unknownKeys)
+	return unknownKeys, nil
+}
+
+func unknownFormatterKeys(prefix string, formatterData map[string]any, formatterType string, registry *yamlfmt.Registry) ([]*UnknownKeyError, error) {
+	if len(formatterData) == 0 {
+		return nil, nil
+	}
+	factory, err := registry.GetFactory(formatterType)
+	if err != nil {
+		// An unknown formatter type is reported when the formatter is made.
+		return nil, nil
+	}
+	knownKeys, err := formatterKeys(factory)
+	if err != nil {
+		return nil, err
+	}
+	unknownErrs := []*UnknownKeyError{}
+	for key := range formatterData {
+		if slices.Contains(knownKeys, key) {
+			continue
+		}
+		unknownErrs = append(unknownErrs, &UnknownKeyError{
+			Key:        prefix + "." + key,
+			Suggestion: suggestKey(key, knownKeys),
+		})
+	}
+	sort.Slice(unknownErrs, func(i, j int) bool {
+		return unknownErrs[i].Key < unknownErrs[j].Key
+	})
+	return unknownErrs, nil
+}
+
+// invalidFormatterValues decodes each known setting in formatterData into
+// the formatter's config on its own, so that a value of the wrong type is
+// reported with the key and source it came from.
+func invalidFormatterValues(prefix string, formatterData map[string]any, formatterType string, registry *yamlfmt.Registry, sources ConfigSources) collections.Errors {
+	factory, err := registry.GetFactory(formatterType)
+	if err != nil {
+		return nil
+	}
+	keys := []string{}
+	for key := range formatterData {
+		if key != "type" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	errs := collections.Errors{}
+	for _, key := range keys {
+		if _, err := factory.NewFormatter(map[string]any{key: formatterData[key]}); err != nil {
+			var decodeErr *mapstructure.Error
+			if errors.As(err, &decodeErr) && len(decodeErr.Errors) == 1 {
+				err = errors.New(decodeErr.Errors[0])
+			}
+			dottedKey := prefix + "." + key
+			source := sources.sourceOf(dottedKey)
+			if line := keyLine(source, dottedKey); line > 0 {
+				source = fmt.Sprintf("%s:%d", source, line)
+			}
+			if source != "" {
+				source += ": "
+			}
+			errs = append(errs, fmt.Errorf("%sinvalid value for %q: %w", source, dottedKey, err))
+		}
+	}
+	return errs
+}
+
+// formatterKeys are the settings that a formatter accepts, taken from
+// the config map of a formatter with the default config. The line ending
+// is always accepted since the command passes it to every formatter.
+func formatterKeys(factory yamlfmt.Factory) ([]string, error) {
+	formatter, err := factory.NewFormatter(nil)
+	if err != nil {
+		return nil, err
+	}
+	configMap, err := formatter.ConfigMap()
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{"type", "line_ending"}
+	for key := range configMap {
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// overrideFormatterType returns the formatter type an override sets, or
+// baseType if it doesn't set one.
+func overrideFormatterType(formatterData map[string]any, baseType string) string {
+	if overrideType, _ := formatterData["type"].(string); overrideType != "" {
+		return overrideType
+	}
+	return baseType
+}
+
+func formatterDataOf(data map[string]any) map[string]any {
+	formatterData, _ := data["formatter"].(map[string]any)
+	return formatterData
+}
+
+// topLevelKeys returns the keys that are valid alongside key, so that
+// unknown keys within an override are compared with the override keys.
+func topLevelKeys(key string) []string {
+	if strings.HasPrefix(key, "overrides[") {
+		return mapstructureKeys(reflect.TypeFor[OverrideConfig]())
+	}
+	return mapstructureKeys(reflect.TypeFor[Config]())
+}
+
+func mapstructureKeys(t reflect.Type) []string {
+	keys := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("mapstructure"), ",")
+		if name != "" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+func lastKeySegment(key string) string {
+	return key[strings.LastIndex(key, ".")+1:]
+}
+
+// suggestKey returns the known key closest to key by edit distance, if
+// it is close enough to plausibly be a typo.
+func suggestKey(key string, knownKeys []string) string {
+	knownKeys = slices.Clone(knownKeys)
+	sort.Strings(knownKeys)
+	best := ""
+	bestDistance := len(key)/3 + 2
+	for _, known := range knownKeys {
+		if distance := levenshtein(key, known); distance < bestDistance {
+			best = known
+			bestDistance = distance
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+var keyIndex = regexp.MustCompile(`\[\d+\]`)
+
+// sourceOf returns the source of key, falling back to the source of the
+// closest parent or any child of key. List indices are ignored since
+// lists are always sourced as a whole.
+func (s ConfigSources) sourceOf(key string) string {
+	key = keyIndex.ReplaceAllString(key, "")
+	for k := key; k != ""; {
+		if source, ok := s[k]; ok {
+			return source
+		}
+		idx := strings.LastIndex(k, ".")
+		if idx < 0 {
+			break
+		}
+		k = k[:idx]
+	}
+	for k, source := range s {
+		if strings.HasPrefix(k, key+".") {
+			return source
+		}
+	}
+	return ""
+}
+
+// keyLine returns the line of key in the yaml file at path, or 0 if it
+// can't be found.
+func keyLine(path string, key string) int {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil || len(doc.Content) == 0 {
+		return 0
+	}
+	node := doc.Content[0]
+	line := 0
+	for _, segment := range strings.Split(key, ".") {
+		name, indices, _ := strings.Cut(segment, "[")
+		if node.Kind != yaml.MappingNode {
+			return line
+		}
+		found := false
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == name {
+				line = node.Content[i].Line
+				node = node.Content[i+1]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return line
+		}
+		for _, index := range strings.Split(indices, "[") {
+			index = strings.TrimSuffix(index, "]")
+			if index == "" {
+				continue
+			}
+			i, err := strconv.Atoi(index)
+			if err != nil || node.Kind != yaml.SequenceNode || i >= len(node.Content) {
+				return line
+			}
+			node = node.Content[i]
+		}
+	}
+	return line
+}
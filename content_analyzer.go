@@ -15,6 +15,8 @@
 package yamlfmt
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"regexp"
 
@@ -25,6 +27,12 @@ type ContentAnalyzer interface {
 	ExcludePathsByContent(paths []string) ([]string, []string, error)
 }
 
+// ContentExplainer is implemented by content analyzers that can describe
+// why a path is excluded.
+type ContentExplainer interface {
+	ExcludeReason(path string) (string, error)
+}
+
 type BasicContentAnalyzer struct {
 	RegexPatterns []*regexp.Regexp
 }
@@ -55,32 +63,104 @@ func (a BasicContentAnalyzer) ExcludePathsByContent(paths []string) ([]string, [
 			continue
 		}
 
-		// Search metadata for ignore
-		metadata, mdErrs := ReadMetadata(content, path)
+		reason, mdErrs := a.excludeReason(content, path)
 		if len(mdErrs) != 0 {
 			pathErrs = append(pathErrs, mdErrs...)
 		}
-		ignoreFound := false
-		for md := range metadata {
-			if md.Type == MetadataIgnore {
-				ignoreFound = true
-				break
-			}
-		}
-		if ignoreFound {
+		if reason != "" {
 			pathsExcluded = append(pathsExcluded, path)
 			pathsToFormat.Remove(path)
-			continue
 		}
+	}
 
-		// Check if content matches any regex
-		matched := false
-		for _, pattern := range a.RegexPatterns {
-			if pattern.Match(content) {
-				matched = true
-			}
+	return pathsToFormat.ToSlice(), pathsExcluded, pathErrs.Combine()
+}
+
+// ExcludeReason describes why the content of path would exclude it from
+// formatting, or returns an empty string if it wouldn't be excluded.
+func (a BasicContentAnalyzer) ExcludeReason(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	reason, mdErrs := a.excludeReason(content, path)
+	return reason, mdErrs.Combine()
+}
+
+// Check is the ContentCheck for the metadata and regex patterns of a, so
+// that it can run in a ContentAnalyzerChain.
+func (a BasicContentAnalyzer) Check(file *AnalyzedFile) (string, error) {
+	content, err := file.Content()
+	if err != nil {
+		return "", err
+	}
+	reason, mdErrs := a.excludeReason(content, file.Path)
+	return reason, errors.Join(mdErrs...)
+}
+
+func (a BasicContentAnalyzer) excludeReason(content []byte, path string) (string, collections.Errors) {
+	// Search metadata for ignore
+	metadata, mdErrs := ReadMetadata(content, path)
+	ignoreLine := 0
+	for md := range metadata {
+		if md.Type == MetadataIgnore && (ignoreLine == 0 || md.LineNum < ignoreLine) {
+			ignoreLine = md.LineNum
+		}
+	}
+	if ignoreLine != 0 {
+		return fmt.Sprintf("%s:%s metadata on line %d", MetadataIdentifier, MetadataIgnore, ignoreLine), mdErrs
+	}
+
+	// Check if content matches any regex
+	for _, pattern := range a.RegexPatterns {
+		if loc := pattern.FindIndex(content); loc != nil {
+			return fmt.Sprintf("regex_exclude pattern %q matches line %d", pattern.String(), lineNumber(content, loc[0])), mdErrs
 		}
-		if matched {
+	}
+	return "", mdErrs
+}
+
+// AnalyzedFile is a file that a ContentAnalyzerChain is checking. Its
+// content is read by the first check that needs it, so a check of the
+// size alone can exclude a large file before it is read.
+type AnalyzedFile struct {
+	Path string
+	Size int64
+
+	content []byte
+	readErr error
+	read    bool
+}
+
+// Content returns the content of the file, reading it the first time.
+func (f *AnalyzedFile) Content() ([]byte, error) {
+	if !f.read {
+		f.content, f.readErr = os.ReadFile(f.Path)
+		f.read = true
+	}
+	return f.content, f.readErr
+}
+
+// ContentCheck is one analyzer in a ContentAnalyzerChain. It returns why
+// file should be excluded from formatting, or an empty string if it
+// shouldn't. An error is reported without excluding the file.
+type ContentCheck func(file *AnalyzedFile) (string, error)
+
+// ContentAnalyzerChain runs its checks on each path in order, and excludes
+// the path for the reason given by the first check that gives one.
+type ContentAnalyzerChain struct {
+	Checks []ContentCheck
+}
+
+func (c ContentAnalyzerChain) ExcludePathsByContent(paths []string) ([]string, []string, error) {
+	pathsToFormat := collections.SliceToSet(paths)
+	pathsExcluded := []string{}
+	pathErrs := collections.Errors{}
+
+	for _, path := range paths {
+		reason, errs := c.excludeReason(path)
+		pathErrs = append(pathErrs, errs...)
+		if reason != "" {
 			pathsExcluded = append(pathsExcluded, path)
 			pathsToFormat.Remove(path)
 		}
@@ -88,3 +168,32 @@ func (a BasicContentAnalyzer) ExcludePathsByContent(paths []string) ([]string, [
 
 	return pathsToFormat.ToSlice(), pathsExcluded, pathErrs.Combine()
 }
+
+// ExcludeReason implements the ContentExplainer interface.
+func (c ContentAnalyzerChain) ExcludeReason(path string) (string, error) {
+	reason, errs := c.excludeReason(path)
+	return reason, errs.Combine()
+}
+
+func (c ContentAnalyzerChain) excludeReason(path string) (string, collections.Errors) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", collections.Errors{err}
+	}
+	file := &AnalyzedFile{Path: path, Size: info.Size()}
+	errs := collections.Errors{}
+	for _, check := range c.Checks {
+		reason, err := check(file)
+		if err != nil {
+			errs = append(errs, err)
+			// There is nothing more to check in a file that can't be read.
+			if file.readErr != nil {
+				break
+			}
+		}
+		if reason != "" {
+			return reason, errs
+		}
+	}
+	return "", errs
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yamlfmt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/google/yamlfmt/pkg/yaml"
+)
+
+// generatedRegex matches the comment that marks a generated file, following
+// the Go convention of `// Code generated ... DO NOT EDIT.` with a yaml
+// comment.
+var generatedRegex = regexp.MustCompile(`(?m)^#\s*Code generated .* DO NOT EDIT\.\s*$`)
+
+// CheckGenerated excludes files with a `# Code generated ... DO NOT EDIT.`
+// comment line.
+func CheckGenerated(file *AnalyzedFile) (string, error) {
+	content, err := file.Content()
+	if err != nil {
+		return "", err
+	}
+	loc := generatedRegex.FindIndex(content)
+	if loc == nil {
+		return "", nil
+	}
+	return fmt.Sprintf("generated file comment on line %d", lineNumber(content, loc[0])), nil
+}
+
+// CheckMaxFileSize excludes files larger than maxSize bytes, without
+// reading them.
+func CheckMaxFileSize(maxSize int) ContentCheck {
+	return func(file *AnalyzedFile) (string, error) {
+		if file.Size <= int64(maxSize) {
+			return "", nil
+		}
+		return fmt.Sprintf("size of %d bytes over max_file_size %d", file.Size, maxSize), nil
+	}
+}
+
+// CheckBinary excludes files that have a NUL byte or aren't valid UTF-8,
+// which includes text in other encodings such as UTF-16.
+func CheckBinary(file *AnalyzedFile) (string, error) {
+	content, err := file.Content()
+	if err != nil {
+		return "", err
+	}
+	if i := bytes.IndexByte(content, 0); i >= 0 {
+		return fmt.Sprintf("binary content, a NUL byte on line %d", lineNumber(content, i)), nil
+	}
+	if !utf8.Valid(content) {
+		for i := 0; i < len(content); {
+			r, size := utf8.DecodeRune(content[i:])
+			if r == utf8.RuneError && size == 1 {
+				return fmt.Sprintf("binary content, invalid UTF-8 on line %d", lineNumber(content, i)), nil
+			}
+			i += size
+		}
+	}
+	return "", nil
+}
+
+// CheckValidYaml excludes files that aren't valid yaml. Files with one of
+// skipExtensions aren't checked, since they aren't yaml as a whole, such
+// as Markdown files with front matter.
+func CheckValidYaml(skipExtensions []string) ContentCheck {
+	return func(file *AnalyzedFile) (string, error) {
+		if ExtensionMatches(file.Path, skipExtensions) {
+			return "", nil
+		}
+		content, err := file.Content()
+		if err != nil {
+			return "", err
+		}
+		content, _ = StripBOM(content)
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		for {
+			var doc yaml.Node
+			err := decoder.Decode(&doc)
+			if errors.Is(err, io.EOF) {
+				return "", nil
+			}
+			if err != nil {
+				return fmt.Sprintf("invalid yaml (%v)", err), nil
+			}
+		}
+	}
+}
+
+func lineNumber(content []byte, offset int) int {
+	return bytes.Count(content[:offset], []byte("\n")) + 1
+}
@@ -0,0 +1,148 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yamlfmt_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/yamlfmt"
+	"github.com/google/yamlfmt/internal/assert"
+)
+
+func TestContentChecks(t *testing.T) {
+	testCases := []struct {
+		name    string
+		check   yamlfmt.ContentCheck
+		content string
+		reason  string
+	}{
+		{
+			name:    "generated",
+			check:   yamlfmt.CheckGenerated,
+			content: "a: 1\n# Code generated by some-tool. DO NOT EDIT.\nb: 2\n",
+			reason:  "generated file comment on line 2",
+		},
+		{
+			name:    "generated marker within a value",
+			check:   yamlfmt.CheckGenerated,
+			content: "a: \"# Code generated by some-tool. DO NOT EDIT.\"\n",
+		},
+		{
+			name:    "under max file size",
+			check:   yamlfmt.CheckMaxFileSize(5),
+			content: "a: 1\n",
+		},
+		{
+			name:    "over max file size",
+			check:   yamlfmt.CheckMaxFileSize(4),
+			content: "a: 1\n",
+			reason:  "size of 5 bytes over max_file_size 4",
+		},
+		{
+			name:    "NUL byte",
+			check:   yamlfmt.CheckBinary,
+			content: "a: 1\nb: \x00\n",
+			reason:  "binary content, a NUL byte on line 2",
+		},
+		{
+			name:    "invalid UTF-8",
+			check:   yamlfmt.CheckBinary,
+			content: "a: 1\nb: 2\nc: \xff\n",
+			reason:  "binary content, invalid UTF-8 on line 3",
+		},
+		{
+			name:    "valid UTF-8",
+			check:   yamlfmt.CheckBinary,
+			content: "\xef\xbb\xbfa: héllo ✓\n",
+		},
+		{
+			name:    "valid yaml",
+			check:   yamlfmt.CheckValidYaml(nil),
+			content: "a: 1\n---\nb: 2\n",
+		},
+		{
+			name:    "invalid yaml",
+			check:   yamlfmt.CheckValidYaml(nil),
+			content: "a: 1\n---\nb: [\n",
+			reason:  "invalid yaml (yaml: line 3: did not find expected node content)",
+		},
+		{
+			name:    "invalid yaml with a skipped extension",
+			check:   yamlfmt.CheckValidYaml([]string{"yaml"}),
+			content: "b: [\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "x.yaml")
+			assert.NilErr(t, os.WriteFile(path, []byte(tc.content), 0644))
+			file := &yamlfmt.AnalyzedFile{Path: path, Size: int64(len(tc.content))}
+			reason, err := tc.check(file)
+			assert.NilErr(t, err)
+			assert.Equal(t, tc.reason, reason)
+		})
+	}
+}
+
+func TestContentAnalyzerChain(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"format.yaml":    "a: 1\n",
+		"ignored.yaml":   "# !yamlfmt!:ignore\n\x00",
+		"binary.yaml":    "a: \x00\n",
+		"generated.yaml": "# Code generated by some-tool. DO NOT EDIT.\na: 1\n",
+		"large.yaml":     "a: " + strings.Repeat("x", 100) + "\n",
+	}
+	paths := []string{}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		assert.NilErr(t, os.WriteFile(path, []byte(content), 0644))
+		paths = append(paths, path)
+	}
+	basicAnalyzer, err := yamlfmt.NewBasicContentAnalyzer(nil)
+	assert.NilErr(t, err)
+	chain := yamlfmt.ContentAnalyzerChain{
+		Checks: []yamlfmt.ContentCheck{
+			yamlfmt.CheckMaxFileSize(50),
+			basicAnalyzer.Check,
+			yamlfmt.CheckBinary,
+			yamlfmt.CheckGenerated,
+		},
+	}
+
+	included, excluded, err := chain.ExcludePathsByContent(paths)
+	assert.NilErr(t, err)
+	assert.SliceEqual(t, []string{filepath.Join(tempDir, "format.yaml")}, included)
+	slices.Sort(excluded)
+	expectedExcluded := []string{
+		filepath.Join(tempDir, "binary.yaml"),
+		filepath.Join(tempDir, "generated.yaml"),
+		filepath.Join(tempDir, "ignored.yaml"),
+		filepath.Join(tempDir, "large.yaml"),
+	}
+	assert.SliceEqual(t, expectedExcluded, excluded)
+
+	// The first check that gives a reason decides it.
+	reason, err := chain.ExcludeReason(filepath.Join(tempDir, "ignored.yaml"))
+	assert.NilErr(t, err)
+	assert.Equal(t, "!yamlfmt!:ignore metadata on line 1", reason)
+	reason, err = chain.ExcludeReason(filepath.Join(tempDir, "format.yaml"))
+	assert.NilErr(t, err)
+	assert.Equal(t, "", reason)
+}
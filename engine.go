@@ -32,6 +32,7 @@ const (
 	OperationDry
 	OperationStdin
 	OperationPrintConfig
+	OperationExplain
 )
 
 type Engine interface {
@@ -17,6 +17,7 @@ package engine
 import (
 	"fmt"
 	"os"
+	"slices"
 
 	"github.com/google/yamlfmt"
 	"github.com/google/yamlfmt/internal/logger"
@@ -24,16 +25,80 @@ import (
 
 // Engine that will process each file one by one consecutively.
 type ConsecutiveEngine struct {
+	// The line separator used to diff files. If empty, the line
+	// separator is detected from the content of each file.
 	LineSepCharacter string
 	Formatter        yamlfmt.Formatter
-	ContinueOnError  bool
-	OutputFormat     EngineOutputFormat
-	Quiet            bool
-	Verbose          bool
+	// Formatters to use for specific paths instead of Formatter.
+	PathFormatters map[string]yamlfmt.Formatter
+	// When set, only the top level nodes that overlap these lines are
+	// formatted.
+	LineRanges []yamlfmt.LineRange
+	// Line ranges to use for specific paths instead of LineRanges.
+	PathLineRanges map[string][]yamlfmt.LineRange
+	// Files with these extensions only have their front matter formatted.
+	FrontMatterExtensions []string
+	// Files with these extensions only have their yaml code blocks
+	// formatted.
+	CodeBlockExtensions []string
+	ContinueOnError     bool
+	OutputFormat        EngineOutputFormat
+	BOM                 yamlfmt.BOMMode
+	Quiet               bool
+	Verbose             bool
+
+	// Paths that were left out of formatting, which the verbose and json
+	// outputs list.
+	ExcludedPaths []yamlfmt.ExcludedPath
 }
 
 func (e *ConsecutiveEngine) FormatContent(content []byte) ([]byte, error) {
-	return e.Formatter.Format(content)
+	return e.formatContent("", content)
+}
+
+// formatContent formats the content of the file at path, or of stdin if
+// path is empty.
+func (e *ConsecutiveEngine) formatContent(path string, content []byte) ([]byte, error) {
+	formatter := e.formatterForPath(path)
+	lineRanges := e.lineRangesForPath(path)
+	frontMatter := path != "" && yamlfmt.ExtensionMatches(path, e.FrontMatterExtensions)
+	codeBlocks := path != "" && yamlfmt.ExtensionMatches(path, e.CodeBlockExtensions)
+
+	// The byte order mark is taken off before formatting so the formatter
+	// never has to deal with it, then put back according to the BOM mode.
+	content, hadBOM := yamlfmt.StripBOM(content)
+	if !frontMatter && !codeBlocks {
+		var formatted []byte
+		var err error
+		if lineRanges != nil {
+			formatted, err = yamlfmt.FormatLineRanges(formatter, content, lineRanges)
+		} else {
+			formatted, err = formatter.Format(content)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return e.BOM.Apply(formatted, hadBOM)
+	}
+
+	formatted := content
+	// Code blocks come after the front matter, so they are formatted
+	// first to keep the line ranges of the front matter in place.
+	if codeBlocks {
+		var blockErrs []yamlfmt.CodeBlockError
+		formatted, blockErrs = yamlfmt.FormatCodeBlocks(formatter, formatted, lineRanges)
+		for _, blockErr := range blockErrs {
+			fmt.Fprintf(os.Stderr, "warning: %s:%d: yaml code block left as is: %v\n", path, blockErr.Line, blockErr.Err)
+		}
+	}
+	if frontMatter {
+		var err error
+		formatted, err = yamlfmt.FormatFrontMatter(formatter, formatted, lineRanges)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return e.BOM.Apply(formatted, hadBOM)
 }
 
 func (e *ConsecutiveEngine) Format(paths []string) (fmt.Stringer, error) {
@@ -62,7 +127,7 @@ func (e *ConsecutiveEngine) Format(paths []string) (fmt.Stringer, error) {
 	if applyErr != nil {
 		return nil, applyErr
 	}
-	return getEngineOutput(e.OutputFormat, yamlfmt.OperationFormat, formatDiffs, e.Quiet, e.Verbose)
+	return getEngineOutput(e.OutputFormat, yamlfmt.OperationFormat, formatDiffs, e.ExcludedPaths, e.Quiet, e.Verbose)
 }
 
 func (e *ConsecutiveEngine) Lint(paths []string) (fmt.Stringer, error) {
@@ -70,10 +135,25 @@ func (e *ConsecutiveEngine) Lint(paths []string) (fmt.Stringer, error) {
 	if len(formatErrs) > 0 {
 		return nil, formatErrs
 	}
+	e.warnMixedLineBreaks(formatDiffs)
 	if formatDiffs.ChangedCount() == 0 {
-		return nil, nil
+		if !e.listsExcluded() {
+			return nil, nil
+		}
+		out, err := getEngineOutput(e.OutputFormat, yamlfmt.OperationLint, formatDiffs, e.ExcludedPaths, e.Quiet, e.Verbose)
+		if err != nil {
+			return nil, err
+		}
+		return LintPassedOutput{out}, nil
 	}
-	return getEngineOutput(e.OutputFormat, yamlfmt.OperationLint, formatDiffs, e.Quiet, e.Verbose)
+	return getEngineOutput(e.OutputFormat, yamlfmt.OperationLint, formatDiffs, e.ExcludedPaths, e.Quiet, e.Verbose)
+}
+
+// LintPassedOutput is the output of Lint when no file has formatting
+// differences, which is only returned when it lists the excluded paths.
+// Unlike other output from Lint, it doesn't mean that the lint failed.
+type LintPassedOutput struct {
+	fmt.Stringer
 }
 
 func (e *ConsecutiveEngine) DryRun(paths []string) (fmt.Stringer, error) {
@@ -81,10 +161,17 @@ func (e *ConsecutiveEngine) DryRun(paths []string) (fmt.Stringer, error) {
 	if len(formatErrs) > 0 {
 		return nil, formatErrs
 	}
-	if formatDiffs.ChangedCount() == 0 {
+	if formatDiffs.ChangedCount() == 0 && !e.listsExcluded() {
 		return nil, nil
 	}
-	return getEngineOutput(e.OutputFormat, yamlfmt.OperationDry, formatDiffs, e.Quiet, e.Verbose)
+	return getEngineOutput(e.OutputFormat, yamlfmt.OperationDry, formatDiffs, e.ExcludedPaths, e.Quiet, e.Verbose)
+}
+
+// listsExcluded reports whether there is output when no file would
+// change. The excluded paths are still worth listing, and json output is
+// always written so it can be parsed.
+func (e *ConsecutiveEngine) listsExcluded() bool {
+	return e.OutputFormat == EngineOutputJSON || (e.Verbose && len(e.ExcludedPaths) > 0)
 }
 
 func (e *ConsecutiveEngine) formatAll(paths []string) (yamlfmt.FileDiffs, FormatErrors) {
@@ -106,16 +193,58 @@ func (e *ConsecutiveEngine) formatFileContent(path string) (*yamlfmt.FileDiff, e
 	if err != nil {
 		return nil, err
 	}
-	formatted, err := e.FormatContent(content)
+	formatted, err := e.formatContent(path, content)
 	if err != nil {
 		return nil, err
 	}
+	lineSep := e.LineSepCharacter
+	if lineSep == "" {
+		style, _ := yamlfmt.DetectLineBreakStyle(content)
+		lineSep, err = style.Separator()
+		if err != nil {
+			return nil, err
+		}
+	}
 	return &yamlfmt.FileDiff{
 		Path: path,
 		Diff: &yamlfmt.FormatDiff{
 			Original:  content,
 			Formatted: formatted,
-			LineSep:   e.LineSepCharacter,
+			LineSep:   lineSep,
 		},
 	}, nil
 }
+
+func (e *ConsecutiveEngine) formatterForPath(path string) yamlfmt.Formatter {
+	if formatter, ok := e.PathFormatters[path]; ok {
+		return formatter
+	}
+	return e.Formatter
+}
+
+func (e *ConsecutiveEngine) lineRangesForPath(path string) []yamlfmt.LineRange {
+	if lineRanges, ok := e.PathLineRanges[path]; ok {
+		return lineRanges
+	}
+	return e.LineRanges
+}
+
+// When line endings are detected per file, a file that mixes LF and CRLF
+// will be normalized to whichever style the majority of its lines use.
+// That is surprising enough to call out when linting.
+func (e *ConsecutiveEngine) warnMixedLineBreaks(formatDiffs yamlfmt.FileDiffs) {
+	if e.LineSepCharacter != "" {
+		return
+	}
+	paths := []string{}
+	for path := range formatDiffs {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	for _, path := range paths {
+		style, mixed := yamlfmt.DetectLineBreakStyle(formatDiffs[path].Diff.Original)
+		if mixed {
+			fmt.Fprintf(os.Stderr, "warning: %s has mixed line endings, they will be normalized to %s\n", path, style)
+		}
+	}
+}
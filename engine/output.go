@@ -30,17 +30,19 @@ const (
 	EngineOutputDefault   EngineOutputFormat = "default"
 	EngineOutputSingeLine EngineOutputFormat = "line"
 	EngineOutputGitlab    EngineOutputFormat = "gitlab"
+	EngineOutputJSON      EngineOutputFormat = "json"
 )
 
-func getEngineOutput(t EngineOutputFormat, operation yamlfmt.Operation, files yamlfmt.FileDiffs, quiet bool, verbose bool) (fmt.Stringer, error) {
+func getEngineOutput(t EngineOutputFormat, operation yamlfmt.Operation, files yamlfmt.FileDiffs, excluded []yamlfmt.ExcludedPath, quiet bool, verbose bool) (fmt.Stringer, error) {
 	switch t {
 	case EngineOutputDefault:
-		return engineOutput{Operation: operation, Files: files, Quiet: quiet, Verbose: verbose}, nil
+		return engineOutput{Operation: operation, Files: files, Excluded: excluded, Quiet: quiet, Verbose: verbose}, nil
 	case EngineOutputSingeLine:
 		return engineOutputSingleLine{Operation: operation, Files: files, Quiet: quiet}, nil
 	case EngineOutputGitlab:
 		return engineOutputGitlab{Operation: operation, Files: files, Compact: quiet}, nil
-
+	case EngineOutputJSON:
+		return engineOutputJSON{Files: files, Excluded: excluded, Compact: quiet}, nil
 	}
 	return nil, fmt.Errorf("unknown output type: %s", t)
 }
@@ -48,11 +50,26 @@ func getEngineOutput(t EngineOutputFormat, operation yamlfmt.Operation, files ya
 type engineOutput struct {
 	Operation yamlfmt.Operation
 	Files     yamlfmt.FileDiffs
+	Excluded  []yamlfmt.ExcludedPath
 	Quiet     bool
 	Verbose   bool
 }
 
 func (eo engineOutput) String() string {
+	result := eo.filesString()
+	if eo.Verbose && len(eo.Excluded) > 0 {
+		if result != "" && !strings.HasSuffix(result, "\n\n") {
+			result = strings.TrimSuffix(result, "\n") + "\n\n"
+		}
+		result += "The following files were excluded:\n"
+		for _, excluded := range eo.Excluded {
+			result += fmt.Sprintf("%s: %s\n", excluded.Path, excluded.Reason)
+		}
+	}
+	return result
+}
+
+func (eo engineOutput) filesString() string {
 	var msg string
 	switch eo.Operation {
 	case yamlfmt.OperationFormat:
@@ -65,6 +82,10 @@ func (eo engineOutput) String() string {
 		msg += eo.Files.StrOutputQuiet()
 		return msg
 	case yamlfmt.OperationLint:
+		// A lint that passed only has output to list the excluded files.
+		if eo.Files.ChangedCount() == 0 {
+			return ""
+		}
 		msg = "The following formatting differences were found:"
 		if eo.Quiet {
 			msg = "The following files had formatting differences:"
@@ -75,7 +96,7 @@ func (eo engineOutput) String() string {
 				msg = "The following files would be formatted:"
 			}
 		} else {
-			return "No files will be formatted."
+			return "No files will be formatted.\n"
 		}
 	}
 	var result string
@@ -138,6 +159,41 @@ func (eo engineOutputGitlab) String() string {
 	return b.String()
 }
 
+type engineOutputJSON struct {
+	Files    yamlfmt.FileDiffs
+	Excluded []yamlfmt.ExcludedPath
+	Compact  bool
+}
+
+func (eo engineOutputJSON) String() string {
+	report := struct {
+		Changed  []string               `json:"changed"`
+		Excluded []yamlfmt.ExcludedPath `json:"excluded"`
+	}{
+		Changed:  []string{},
+		Excluded: eo.Excluded,
+	}
+	for path, fileDiff := range eo.Files {
+		if fileDiff.Diff.Changed() {
+			report.Changed = append(report.Changed, path)
+		}
+	}
+	sort.Strings(report.Changed)
+	if report.Excluded == nil {
+		report.Excluded = []yamlfmt.ExcludedPath{}
+	}
+
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	if !eo.Compact {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(report); err != nil {
+		panic(err)
+	}
+	return b.String()
+}
+
 // byPath is used to sort by Location.Path.
 type byPath []gitlab.CodeQuality
 
@@ -36,6 +36,7 @@ type Config struct {
 	TrimTrailingWhitespace    bool                       `mapstructure:"trim_trailing_whitespace"`
 	EOFNewline                bool                       `mapstructure:"eof_newline"`
 	StripDirectives           bool                       `mapstructure:"strip_directives"`
+	GoTemplates               bool                       `mapstructure:"go_templates"`
 	ArrayIndent               int                        `mapstructure:"array_indent"`
 	IndentRootArray           bool                       `mapstructure:"indent_root_array"`
 	DisableAliasKeyCorrection bool                       `mapstructure:"disable_alias_key_correction"`
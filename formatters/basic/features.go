@@ -26,7 +26,11 @@ func ConfigureFeaturesFromConfig(config *Config) yamlfmt.FeatureList {
 	if err != nil {
 		lineSep = "\n"
 	}
-	configuredFeatures := []yamlfmt.Feature{}
+	// Frozen regions are taken out before every other feature runs its
+	// BeforeAction, and put back after every AfterAction, so that they are
+	// left exactly as written.
+	freezeRegions, restoreFrozenRegions := hotfix.MakeFeaturesFrozenRegions(lineSep)
+	configuredFeatures := []yamlfmt.Feature{freezeRegions}
 	if config.RetainLineBreaks || config.RetainLineBreaksSingle {
 		configuredFeatures = append(
 			configuredFeatures,
@@ -51,7 +55,16 @@ func ConfigureFeaturesFromConfig(config *Config) yamlfmt.FeatureList {
 			hotfix.MakeFeatureStripDirectives(lineSep),
 		)
 	}
-	return configuredFeatures
+	// Go templates are masked after every other feature has run its
+	// BeforeAction, and restored after every AfterAction, so the other
+	// features only see the masked yaml.
+	if config.GoTemplates {
+		configuredFeatures = append(
+			configuredFeatures,
+			hotfix.MakeFeatureGoTemplates(lineSep),
+		)
+	}
+	return append(configuredFeatures, restoreFrozenRegions)
 }
 
 func ConfigureYAMLFeaturesFromConfig(config *Config) (yamlFeatures.YAMLFeatureList, error) {
@@ -41,9 +41,28 @@ func (f *BasicFormatter) Type() string {
 }
 
 func (f *BasicFormatter) Format(input []byte) ([]byte, error) {
+	if documents := splitIgnoredDocuments(input); documents != nil {
+		return f.formatAroundIgnoredDocuments(documents)
+	}
+	return f.format(input)
+}
+
+func (f *BasicFormatter) format(input []byte) ([]byte, error) {
+	// With automatic line endings the style is detected from the input,
+	// so the features that depend on the line separator are configured
+	// for each call instead of once up front.
+	config := f.Config
+	features := f.Features
+	if config.LineEnding == yamlfmt.LineBreakStyleAuto {
+		detected := *f.Config
+		detected.LineEnding, _ = yamlfmt.DetectLineBreakStyle(input)
+		config = &detected
+		features = ConfigureFeaturesFromConfig(config)
+	}
+
 	// Run all features with BeforeActions
 	ctx := context.Background()
-	ctx, yamlContent, err := f.Features.ApplyFeatures(ctx, input, yamlfmt.FeatureApplyBefore)
+	ctx, yamlContent, err := features.ApplyFeatures(ctx, input, yamlfmt.FeatureApplyBefore)
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +95,7 @@ func (f *BasicFormatter) Format(input []byte) ([]byte, error) {
 	}
 
 	var b bytes.Buffer
-	e := f.getNewEncoder(&b)
+	e := f.getNewEncoder(&b, config)
 	for _, doc := range documents {
 		err := e.Encode(&doc)
 		if err != nil {
@@ -85,7 +104,7 @@ func (f *BasicFormatter) Format(input []byte) ([]byte, error) {
 	}
 
 	// Run all features with AfterActions
-	_, resultYaml, err := f.Features.ApplyFeatures(ctx, b.Bytes(), yamlfmt.FeatureApplyAfter)
+	_, resultYaml, err := features.ApplyFeatures(ctx, b.Bytes(), yamlfmt.FeatureApplyAfter)
 	if err != nil {
 		return nil, err
 	}
@@ -101,33 +120,33 @@ func (f *BasicFormatter) getNewDecoder(reader io.Reader) *yaml.Decoder {
 	return d
 }
 
-func (f *BasicFormatter) getNewEncoder(buf *bytes.Buffer) *yaml.Encoder {
+func (f *BasicFormatter) getNewEncoder(buf *bytes.Buffer, config *Config) *yaml.Encoder {
 	e := yaml.NewEncoder(buf)
-	e.SetIndent(f.Config.Indent)
+	e.SetIndent(config.Indent)
 
-	if f.Config.LineLength > 0 {
-		e.SetWidth(f.Config.LineLength)
+	if config.LineLength > 0 {
+		e.SetWidth(config.LineLength)
 	}
 
-	if f.Config.LineEnding == yamlfmt.LineBreakStyleCRLF {
+	if config.LineEnding == yamlfmt.LineBreakStyleCRLF {
 		e.SetLineBreakStyle(yaml.LineBreakStyleCRLF)
 	}
 
-	e.SetExplicitDocumentStart(f.Config.IncludeDocumentStart)
-	e.SetAssumeBlockAsLiteral(f.Config.ScanFoldedAsLiteral)
-	e.SetIndentlessBlockSequence(f.Config.IndentlessArrays)
-	e.SetDropMergeTag(f.Config.DropMergeTag)
-	e.SetPadLineComments(f.Config.PadLineComments)
+	e.SetExplicitDocumentStart(config.IncludeDocumentStart)
+	e.SetAssumeBlockAsLiteral(config.ScanFoldedAsLiteral)
+	e.SetIndentlessBlockSequence(config.IndentlessArrays)
+	e.SetDropMergeTag(config.DropMergeTag)
+	e.SetPadLineComments(config.PadLineComments)
 
-	if f.Config.ArrayIndent > 0 {
-		e.SetArrayIndent(f.Config.ArrayIndent)
+	if config.ArrayIndent > 0 {
+		e.SetArrayIndent(config.ArrayIndent)
 	}
-	e.SetIndentRootArray(f.Config.IndentRootArray)
+	e.SetIndentRootArray(config.IndentRootArray)
 
-	// Yes I know I could SetCorrectAliasKeys(!f.Config.DisableAliasKeyCorrection)
+	// Yes I know I could SetCorrectAliasKeys(!config.DisableAliasKeyCorrection)
 	// but I know myself and I know I'll get confused and have to go look up
 	// the source again next time I look and forget.
-	if !f.Config.DisableAliasKeyCorrection {
+	if !config.DisableAliasKeyCorrection {
 		e.SetCorrectAliasKeys(true)
 	}
 
@@ -71,6 +71,27 @@ a:`,
 			input:  "a:\r\nb:\r\nc:\r\n",
 			expect: "a:\nb:\nc:\n",
 		},
+		{
+			name: "auto line ending detects crlf",
+			config: map[string]any{
+				"line_ending":        "auto",
+				"retain_line_breaks": true,
+			},
+			input:  "a:   1\r\n\r\nb: 2\r\n",
+			expect: "a: 1\r\n\r\nb: 2\r\n",
+
+			skipLineEndNormalization: true,
+		},
+		{
+			name: "auto line ending detects lf",
+			config: map[string]any{
+				"line_ending": "auto",
+			},
+			input:  "a:   1\nb: 2\n",
+			expect: "a: 1\nb: 2\n",
+
+			skipLineEndNormalization: true,
+		},
 		{
 			name:  "emoji support",
 			input: `a: 😊`,
@@ -371,6 +392,328 @@ c: 3
 	}
 }
 
+func TestGoTemplates(t *testing.T) {
+	testCases := []struct {
+		name      string
+		input     string
+		expect    string
+		formatErr string
+	}{
+		{
+			name: "inline and control flow actions",
+			input: `metadata:
+  name:    {{ include "chart.fullname" . }}
+  labels:
+    {{- include "chart.labels" . | nindent 4 }}
+spec:
+  {{- if not .Values.autoscaling.enabled }}
+  replicas:    {{ .Values.replicaCount }}
+  {{- end }}
+  image: "{{ .Values.image.repository }}:{{ .Values.image.tag }}"
+  args: [ {{ .Values.arg }},   "b" ]
+`,
+			expect: `metadata:
+  name: {{ include "chart.fullname" . }}
+  labels:
+    {{- include "chart.labels" . | nindent 4 }}
+spec:
+  {{- if not .Values.autoscaling.enabled }}
+  replicas: {{ .Values.replicaCount }}
+  {{- end }}
+  image: "{{ .Values.image.repository }}:{{ .Values.image.tag }}"
+  args: [{{ .Values.arg }}, "b"]
+`,
+		},
+		{
+			name: "braces in strings and comments",
+			input: `{{- /* a comment
+  over lines with }} */}}
+value:   {{ .Values.x | default "}}" | quote }}
+`,
+			expect: `{{- /* a comment
+  over lines with }} */}}
+value: {{ .Values.x | default "}}" | quote }}
+`,
+		},
+		{
+			name: "control lines follow the indent",
+			input: `x:
+    {{- if .Values.foo }}
+    y: 1
+    {{- /* a comment
+      over lines */}}
+    {{- end }}
+`,
+			expect: `x:
+  {{- if .Values.foo }}
+  y: 1
+  {{- /* a comment
+    over lines */}}
+  {{- end }}
+`,
+		},
+		{
+			name: "nested control lines follow the indent",
+			input: `metadata:
+    labels:
+        {{- include "chart.labels" . | nindent 4 }}
+spec:
+    a: 1
+`,
+			expect: `metadata:
+  labels:
+    {{- include "chart.labels" . | nindent 4 }}
+spec:
+  a: 1
+`,
+		},
+		{
+			name: "ambiguous structure",
+			input: `env:
+  {{- if .Values.list }}
+  - a
+  {{- else }}
+  b: 1
+  {{- end }}
+`,
+			formatErr: "ambiguous",
+		},
+		{
+			name:      "multi-line action within a line",
+			input:     "a: {{ .x\n  }}\n",
+			formatErr: "runs over several lines",
+		},
+		{
+			name:      "unclosed action",
+			input:     "a: {{ .x\n",
+			formatErr: "never closed",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := factory.NewFormatter(map[string]any{
+				"go_templates":       true,
+				"retain_line_breaks": true,
+			})
+			require.NoError(t, err)
+			got, err := f.Format([]byte(tc.input))
+			if tc.formatErr != "" {
+				require.ErrorContains(t, err, tc.formatErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, string(got))
+		})
+	}
+}
+
+func TestFrozenRegions(t *testing.T) {
+	testCases := []struct {
+		name      string
+		config    map[string]any
+		input     string
+		expect    string
+		formatErr string
+	}{
+		{
+			name: "off and on",
+			input: `a:    1
+# !yamlfmt!:off
+matrix: [ 1,  0,
+          0,  1 ]
+
+b:    {x:   1}
+# !yamlfmt!:on
+c:    3
+`,
+			expect: `a: 1
+# !yamlfmt!:off
+matrix: [ 1,  0,
+          0,  1 ]
+
+b:    {x:   1}
+# !yamlfmt!:on
+c: 3
+`,
+		},
+		{
+			name: "off to the end of the document",
+			input: `a:    1
+# !yamlfmt!:off
+b:    2
+---
+c:    3
+`,
+			expect: `a: 1
+# !yamlfmt!:off
+b:    2
+---
+c: 3
+`,
+		},
+		{
+			name: "off and on are re-indented as a block",
+			input: `root:
+    a:    1
+    # !yamlfmt!:off
+    matrix: [ 1,  0,
+              0,  1 ]
+    # !yamlfmt!:on
+    b:    2
+`,
+			expect: `root:
+  a: 1
+  # !yamlfmt!:off
+  matrix: [ 1,  0,
+            0,  1 ]
+  # !yamlfmt!:on
+  b: 2
+`,
+		},
+		{
+			name: "ignore next moves with its parent",
+			input: `root:
+    a:    1
+    # !yamlfmt!:ignore-next
+    keep:
+        x:     1
+
+        y:  [a,   b]
+    d:    4
+`,
+			expect: `root:
+  a: 1
+  # !yamlfmt!:ignore-next
+  keep:
+      x:     1
+
+      y:  [a,   b]
+  d: 4
+`,
+		},
+		{
+			name: "ignore next sequence item",
+			input: `list:
+- a:   1
+  # !yamlfmt!:ignore-next
+- b:     2
+  c:     3
+- e:   5
+`,
+			expect: `list:
+  - a: 1
+    # !yamlfmt!:ignore-next
+  - b:     2
+    c:     3
+  - e: 5
+`,
+		},
+		{
+			name: "with retain line breaks and trailing whitespace",
+			config: map[string]any{
+				"retain_line_breaks":       true,
+				"trim_trailing_whitespace": true,
+			},
+			input:  "a:    1\n\n# !yamlfmt!:ignore-next\nb:    2   \n\nc:    3\n",
+			expect: "a: 1\n\n# !yamlfmt!:ignore-next\nb:    2   \n\nc: 3\n",
+		},
+		{
+			name:      "on without off",
+			input:     "a: 1\n# !yamlfmt!:on\n",
+			formatErr: "has no !yamlfmt!:off before it",
+		},
+		{
+			name:      "ignore next without a node",
+			input:     "a: 1\n# !yamlfmt!:ignore-next\n",
+			formatErr: "has no node after it",
+		},
+		{
+			name:      "region that splits a node",
+			input:     "a: 1\n# !yamlfmt!:off\nb:\n# !yamlfmt!:on\n  c: 1\n",
+			formatErr: "make sure each region covers whole nodes",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := factory.NewFormatter(tc.config)
+			require.NoError(t, err)
+			got, err := f.Format([]byte(tc.input))
+			if tc.formatErr != "" {
+				require.ErrorContains(t, err, tc.formatErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, string(got))
+		})
+	}
+}
+
+func TestIgnoredDocuments(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config map[string]any
+		input  string
+		expect string
+	}{
+		{
+			name: "ignored document between formatted ones",
+			input: `# header
+a:    1
+---
+# !yamlfmt!:ignore-document
+b:    {x:   1}
+
+---
+c:    3
+---
+d:    4
+`,
+			expect: `# header
+a: 1
+---
+# !yamlfmt!:ignore-document
+b:    {x:   1}
+
+---
+c: 3
+---
+d: 4
+`,
+		},
+		{
+			name:   "first document ignored",
+			input:  "# !yamlfmt!:ignore-document\nb:    2\n---\nc:    3\n",
+			expect: "# !yamlfmt!:ignore-document\nb:    2\n---\nc: 3\n",
+		},
+		{
+			name:   "last document ignored without a final line break",
+			input:  "a:    1\n---\nb:    2 # !yamlfmt!:ignore-document\n---\n# !yamlfmt!:ignore-document\nc:    3",
+			expect: "a: 1\n---\nb: 2 # !yamlfmt!:ignore-document\n---\n# !yamlfmt!:ignore-document\nc:    3",
+		},
+		{
+			name:   "with include document start",
+			config: map[string]any{"include_document_start": true},
+			input:  "a:    1\n---\n# !yamlfmt!:ignore-document\nb:    2\n---\nc:    3\n",
+			expect: "---\na: 1\n---\n# !yamlfmt!:ignore-document\nb:    2\n---\nc: 3\n",
+		},
+		{
+			name:   "crlf",
+			config: map[string]any{"line_ending": "crlf"},
+			input:  "a:    1\r\n---\r\n# !yamlfmt!:ignore-document\r\nb:    2\r\n---\r\nc:    3\r\n",
+			expect: "a: 1\r\n---\r\n# !yamlfmt!:ignore-document\r\nb:    2\r\n---\r\nc: 3\r\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := factory.NewFormatter(tc.config)
+			require.NoError(t, err)
+			got, err := f.Format([]byte(tc.input))
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, string(got))
+		})
+	}
+}
+
 func stripTrailingNewline(s string) string {
 	// strip trailing \n or \r\n characters
 	if len(s) > 0 {
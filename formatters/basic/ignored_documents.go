@@ -0,0 +1,126 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"bytes"
+
+	"github.com/google/yamlfmt"
+)
+
+// streamDocument is the text of one document in a stream, starting with the
+// document start marker that begins it, if there is one.
+type streamDocument struct {
+	content []byte
+	ignored bool
+}
+
+// splitIgnoredDocuments splits input into its documents if any of them has
+// `!yamlfmt!:ignore-document` metadata in a comment line of its own, and
+// returns nil otherwise. Comments and directives before a document start
+// marker belong to the document that follows.
+func splitIgnoredDocuments(input []byte) []streamDocument {
+	if !bytes.Contains(input, []byte(yamlfmt.MetadataIdentifier+":"+string(yamlfmt.MetadataIgnoreDocument))) {
+		return nil
+	}
+	documents := []streamDocument{}
+	var current streamDocument
+	hasContent := false
+	for _, line := range bytes.SplitAfter(input, []byte("\n")) {
+		if isDocumentStart(line) && hasContent {
+			documents = append(documents, current)
+			current = streamDocument{}
+			hasContent = false
+		}
+		current.content = append(current.content, line...)
+		trimmed := bytes.TrimSpace(line)
+		switch {
+		case isDocumentStart(line):
+			rest := bytes.TrimSpace(line[3:])
+			hasContent = hasContent || (len(rest) > 0 && rest[0] != '#')
+		case len(trimmed) > 0 && trimmed[0] == '#':
+			current.ignored = current.ignored || isIgnoreDocumentComment(trimmed)
+		case len(trimmed) > 0 && trimmed[0] != '%':
+			hasContent = true
+		}
+	}
+	if len(current.content) > 0 {
+		documents = append(documents, current)
+	}
+
+	for _, document := range documents {
+		if document.ignored {
+			return documents
+		}
+	}
+	return nil
+}
+
+func isIgnoreDocumentComment(comment []byte) bool {
+	// Malformed metadata is reported when paths are analyzed.
+	metadata, _ := yamlfmt.ReadMetadata(comment, "")
+	for md := range metadata {
+		if md.Type == yamlfmt.MetadataIgnoreDocument {
+			return true
+		}
+	}
+	return false
+}
+
+func isDocumentStart(line []byte) bool {
+	rest, ok := bytes.CutPrefix(line, []byte("---"))
+	return ok && (len(rest) == 0 || bytes.ContainsRune([]byte(" \t\r\n"), rune(rest[0])))
+}
+
+// formatAroundIgnoredDocuments leaves the ignored documents as written,
+// and formats each run of the other documents together.
+func (f *BasicFormatter) formatAroundIgnoredDocuments(documents []streamDocument) ([]byte, error) {
+	var result bytes.Buffer
+	for i := 0; i < len(documents); {
+		var content []byte
+		if documents[i].ignored {
+			content = documents[i].content
+			i++
+		} else {
+			var run []byte
+			for ; i < len(documents) && !documents[i].ignored; i++ {
+				run = append(run, documents[i].content...)
+			}
+			formatted, err := f.format(run)
+			if err != nil {
+				return nil, err
+			}
+			// The formatter only writes document start markers between the
+			// documents it formats, so the one that separates the run from
+			// the ignored document before it is put back.
+			if result.Len() > 0 && !isDocumentStart(formatted) {
+				formatted = append([]byte("---"+lineBreakOf(run)), formatted...)
+			}
+			content = formatted
+		}
+		if result.Len() > 0 && !bytes.HasSuffix(result.Bytes(), []byte("\n")) {
+			result.WriteString(lineBreakOf(result.Bytes()))
+		}
+		result.Write(content)
+	}
+	return result.Bytes(), nil
+}
+
+func lineBreakOf(content []byte) string {
+	if bytes.Contains(content, []byte("\r\n")) {
+		return "\r\n"
+	}
+	return "\n"
+}
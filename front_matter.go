@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yamlfmt
+
+import (
+	"bytes"
+
+	"github.com/google/yamlfmt/internal/logger"
+)
+
+// FrontMatter is a YAML block at the start of a file, such as a Markdown
+// document, between two `---` lines.
+type FrontMatter struct {
+	// The opening `---` line.
+	Open []byte
+	// The YAML between the delimiter lines.
+	Content []byte
+	// The closing `---` or `...` line, followed by the rest of the file.
+	Rest []byte
+}
+
+// SplitFrontMatter splits the front matter off the start of content. It
+// reports false if content doesn't start with a `---` line, or if there
+// is no closing `---` or `...` line.
+func SplitFrontMatter(content []byte) (FrontMatter, bool) {
+	open, _, found := bytes.Cut(content, []byte("\n"))
+	if !found || !isDelimiterLine(open, "---") {
+		return FrontMatter{}, false
+	}
+	openLen := len(open) + 1
+	for offset := openLen; offset < len(content); {
+		line, _, _ := bytes.Cut(content[offset:], []byte("\n"))
+		if isDelimiterLine(line, "---") || isDelimiterLine(line, "...") {
+			return FrontMatter{
+				Open:    content[:openLen],
+				Content: content[openLen:offset],
+				Rest:    content[offset:],
+			}, true
+		}
+		offset += len(line) + 1
+	}
+	return FrontMatter{}, false
+}
+
+// isDelimiterLine reports whether line is the delimiter alone, allowing
+// trailing whitespace.
+func isDelimiterLine(line []byte, delimiter string) bool {
+	return string(bytes.TrimRight(line, " \t\r")) == delimiter
+}
+
+// FormatFrontMatter formats only the front matter of content, and leaves
+// the rest of content byte for byte as it was. Content without front
+// matter is returned as is. If ranges isn't nil, only the top level nodes
+// of the front matter that overlap the ranges are formatted, where the
+// ranges are line numbers of the whole content.
+func FormatFrontMatter(formatter Formatter, content []byte, ranges []LineRange) ([]byte, error) {
+	frontMatter, ok := SplitFrontMatter(content)
+	if !ok {
+		logger.Debug(logger.DebugCodeDiffs, "no front matter found, leaving content as is")
+		return content, nil
+	}
+	if len(bytes.TrimSpace(frontMatter.Content)) == 0 {
+		return content, nil
+	}
+
+	var formatted []byte
+	var err error
+	if ranges != nil {
+		// The front matter starts on the line after the opening delimiter.
+		shifted := make([]LineRange, 0, len(ranges))
+		for _, r := range ranges {
+			shifted = append(shifted, LineRange{Start: r.Start - 1, End: r.End - 1})
+		}
+		formatted, err = FormatLineRanges(formatter, frontMatter.Content, shifted)
+	} else {
+		formatted, err = formatter.Format(frontMatter.Content)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// The front matter is a single document, so a document start that the
+	// formatter adds would end it early.
+	if isDocumentMarker(formatted, "---") {
+		_, formatted, _ = bytes.Cut(formatted, []byte("\n"))
+	}
+	// The rest of the file is left as it is, so the front matter keeps
+	// the line breaks of the file rather than the configured ones.
+	formatted = withLineBreaksOf(formatted, frontMatter.Open)
+
+	result := make([]byte, 0, len(content))
+	result = append(result, frontMatter.Open...)
+	result = append(result, formatted...)
+	return append(result, frontMatter.Rest...), nil
+}
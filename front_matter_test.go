@@ -0,0 +1,78 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yamlfmt_test
+
+import (
+	"testing"
+
+	"github.com/google/yamlfmt"
+	"github.com/google/yamlfmt/formatters/basic"
+	"github.com/google/yamlfmt/internal/assert"
+)
+
+func TestFormatFrontMatter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		ranges   []yamlfmt.LineRange
+		expected string
+	}{
+		{
+			name:     "formats front matter only",
+			content:  "---\ntitle:    Hello\ntags: [a,   b]\n---\n# Title\n\nkey:   value\n---\n",
+			expected: "---\ntitle: Hello\ntags: [a, b]\n---\n# Title\n\nkey:   value\n---\n",
+		},
+		{
+			name:     "closing dots",
+			content:  "---\na:   1\n...\nbody  \n",
+			expected: "---\na: 1\n...\nbody  \n",
+		},
+		{
+			name:     "crlf",
+			content:  "---\r\na:   1\r\n---\r\nbody\r\n",
+			expected: "---\r\na: 1\r\n---\r\nbody\r\n",
+		},
+		{
+			name:     "no front matter",
+			content:  "# Title\n---\na:   1\n---\n",
+			expected: "# Title\n---\na:   1\n---\n",
+		},
+		{
+			name:     "unterminated front matter",
+			content:  "---\na:   1\n",
+			expected: "---\na:   1\n",
+		},
+		{
+			name:     "empty front matter",
+			content:  "---\n---\nbody\n",
+			expected: "---\n---\nbody\n",
+		},
+		{
+			name:     "line ranges of the whole file",
+			content:  "---\na:   1\nb:   2\n---\nc:   3\n",
+			ranges:   []yamlfmt.LineRange{{Start: 3, End: 5}},
+			expected: "---\na:   1\nb: 2\n---\nc:   3\n",
+		},
+	}
+	formatter, err := (&basic.BasicFormatterFactory{}).NewFormatter(map[string]any{"include_document_start": true})
+	assert.NilErr(t, err)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			formatted, err := yamlfmt.FormatFrontMatter(formatter, []byte(tc.content), tc.ranges)
+			assert.NilErr(t, err)
+			assert.Equal(t, tc.expected, string(formatted))
+		})
+	}
+}
@@ -156,6 +156,30 @@ func TestGitLabOutput(t *testing.T) {
 	}.Run(t)
 }
 
+func TestJSONOutput(t *testing.T) {
+	TestCase{
+		Dir:     "json_output",
+		Command: yamlfmtWithArgs("-dry -output_format json ."),
+		Update:  *updateFlag,
+	}.Run(t)
+}
+
+func TestMetadataErrorsJSON(t *testing.T) {
+	TestCase{
+		Dir:     "metadata_errors_json",
+		Command: yamlfmtWithArgs("-dry -output_format json ."),
+		Update:  *updateFlag,
+	}.Run(t)
+}
+
+func TestAnalysisErrorsJSON(t *testing.T) {
+	TestCase{
+		Dir:     "analysis_errors_json",
+		Command: yamlfmtWithArgs("-dry -output_format json ."),
+		Update:  *updateFlag,
+	}.Run(t)
+}
+
 func TestPatternFile(t *testing.T) {
 	TestCase{
 		Dir:     "pattern_file",
@@ -211,3 +235,165 @@ func TestKyamlFlag(t *testing.T) {
 		Update:  *updateFlag,
 	}.Run(t)
 }
+
+func TestLineEndingAuto(t *testing.T) {
+	TestCase{
+		Dir:     "line_ending_auto",
+		Command: yamlfmtWithArgs("."),
+		Update:  *updateFlag,
+	}.Run(t)
+}
+
+func TestLintJSONPassed(t *testing.T) {
+	TestCase{
+		Dir:     "lint_json_passed",
+		Command: yamlfmtWithArgs("-lint -output_format json ."),
+		Update:  *updateFlag,
+	}.Run(t)
+}
+
+func TestMixedLineEndingsLint(t *testing.T) {
+	TestCase{
+		Dir:     "mixed_line_endings_lint",
+		Command: yamlfmtWithArgs("-lint -output_format line ."),
+		Update:  *updateFlag,
+		IsError: true,
+	}.Run(t)
+}
+
+func TestOverrides(t *testing.T) {
+	TestCase{
+		Dir:     "overrides",
+		Command: yamlfmtWithArgs("."),
+		Update:  *updateFlag,
+	}.Run(t)
+}
+
+func TestOverridesSubdir(t *testing.T) {
+	TestCase{
+		Dir:     "overrides_subdir",
+		Command: yamlfmtWithArgs("-conf ../.yamlfmt ."),
+		WorkDir: "sub",
+		Update:  *updateFlag,
+	}.Run(t)
+}
+
+func TestNestedConfigs(t *testing.T) {
+	TestCase{
+		Dir:     "nested_configs",
+		Command: yamlfmtWithArgs("."),
+		Update:  *updateFlag,
+	}.Run(t)
+}
+
+func TestPrintConfExtends(t *testing.T) {
+	TestCase{
+		Dir:     "print_conf_extends",
+		Command: yamlfmtWithArgs("-print_conf"),
+		Update:  *updateFlag,
+	}.Run(t)
+}
+
+func TestEditorConfig(t *testing.T) {
+	TestCase{
+		Dir:     "editorconfig",
+		Command: yamlfmtWithArgs("."),
+		Update:  *updateFlag,
+	}.Run(t)
+}
+
+func TestStrictConfig(t *testing.T) {
+	TestCase{
+		Dir:     "strict_config",
+		Command: yamlfmtWithArgs("."),
+		Update:  *updateFlag,
+		IsError: true,
+	}.Run(t)
+}
+
+func TestInit(t *testing.T) {
+	TestCase{
+		Dir:     "init",
+		Command: yamlfmtWithArgs("-init"),
+		Update:  *updateFlag,
+	}.Run(t)
+}
+
+func TestInitInfer(t *testing.T) {
+	TestCase{
+		Dir:     "init_infer",
+		Command: yamlfmtWithArgs("-init -init_infer"),
+		Update:  *updateFlag,
+	}.Run(t)
+}
+
+func TestExplain(t *testing.T) {
+	TestCase{
+		Dir:     "explain",
+		Command: yamlfmtWithArgs("-explain k8s/deployment.yaml ."),
+		Update:  *updateFlag,
+	}.Run(t)
+}
+
+func TestPrintConfEnvAndSet(t *testing.T) {
+	TestCase{
+		Dir:     "print_conf_env_and_set",
+		Command: yamlfmtWithArgs("-print_conf -set gitignore_excludes=false -set exclude=build/,dist/ -set formatter.max_line_length=100"),
+		Env: []string{
+			"YAMLFMT_GITIGNORE_EXCLUDES=true",
+			"YAMLFMT_FORMATTER_INDENT=6",
+			"YAMLFMT_LINE_ENDING=crlf",
+			"YAMLFMT_NOT_A_KEY=1",
+		},
+		Update: *updateFlag,
+	}.Run(t)
+}
+
+func TestFormatterFlagTyped(t *testing.T) {
+	TestCase{
+		Dir:     "formatter_flag_typed",
+		Command: yamlfmtWithArgs("-formatter indent=4,force_array_style=block,pad_line_comments=2 -formatter include_document_start=true x.yaml"),
+		Update:  *updateFlag,
+	}.Run(t)
+}
+
+func TestFormatterFlagInvalid(t *testing.T) {
+	TestCase{
+		Dir:     "formatter_flag_invalid",
+		Command: yamlfmtWithArgs("-formatter indent=[4] x.yaml"),
+		Update:  *updateFlag,
+		IsError: true,
+	}.Run(t)
+}
+
+func TestLines(t *testing.T) {
+	TestCase{
+		Dir:     "lines",
+		Command: yamlfmtWithArgs("-lines 3 x.yaml"),
+		Update:  *updateFlag,
+	}.Run(t)
+}
+
+func TestFrontMatter(t *testing.T) {
+	TestCase{
+		Dir:     "front_matter",
+		Command: yamlfmtWithArgs("."),
+		Update:  *updateFlag,
+	}.Run(t)
+}
+
+func TestCodeBlocks(t *testing.T) {
+	TestCase{
+		Dir:     "code_blocks",
+		Command: yamlfmtWithArgs("."),
+		Update:  *updateFlag,
+	}.Run(t)
+}
+
+func TestYamlfmtIgnore(t *testing.T) {
+	TestCase{
+		Dir:     "yamlfmtignore",
+		Command: yamlfmtWithArgs("."),
+		Update:  *updateFlag,
+	}.Run(t)
+}
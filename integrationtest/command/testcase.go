@@ -19,6 +19,7 @@ package command
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -34,8 +35,12 @@ const (
 )
 
 type TestCase struct {
-	Dir        string
-	Command    string
+	Dir     string
+	Command string
+	// The directory within the test directory to run the command in, if
+	// not the test directory itself.
+	WorkDir    string
+	Env        []string
 	IsError    bool
 	Update     bool
 	ShowStdout bool
@@ -89,13 +94,17 @@ func (tc TestCase) command(stdoutBuf *bytes.Buffer, stderrBuf *bytes.Buffer) *ex
 			cmdArgs = append(cmdArgs, arg)
 		}
 	}
-	return &exec.Cmd{
+	cmd := &exec.Cmd{
 		Path:   cmdArgs[0], // This is just the path to the command
 		Args:   cmdArgs,    // Args needs to be an array of everything including the command
 		Stdout: stdoutBuf,
 		Stderr: stderrBuf,
-		Dir:    tc.tempDir,
+		Dir:    filepath.Join(tc.tempDir, tc.WorkDir),
 	}
+	if len(tc.Env) > 0 {
+		cmd.Env = append(os.Environ(), tc.Env...)
+	}
+	return cmd
 }
 
 func (tc TestCase) goldenStdout(stdoutResult []byte) error {
@@ -0,0 +1,334 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package editorconfig resolves the properties that `.editorconfig` files
+// assign to a path, following https://spec.editorconfig.org.
+package editorconfig
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const FileName = ".editorconfig"
+
+// Properties are the lowercased property names and values that apply to
+// a path. Properties set to `unset` are removed.
+type Properties map[string]string
+
+type section struct {
+	pattern    *glob
+	properties [][2]string
+}
+
+type file struct {
+	root     bool
+	sections []section
+}
+
+// Resolver resolves properties for paths, caching every `.editorconfig`
+// file that it reads.
+type Resolver struct {
+	files map[string]*file
+}
+
+func NewResolver() *Resolver {
+	return &Resolver{files: map[string]*file{}}
+}
+
+// Properties returns the properties for path from every `.editorconfig`
+// file in the directories above it, up to the first one marked with
+// `root = true`. Closer files take precedence over further ones, and
+// later sections within a file take precedence over earlier ones.
+func (r *Resolver) Properties(path string) (Properties, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := []*file{}
+	for dir := filepath.Dir(absPath); ; dir = filepath.Dir(dir) {
+		f, err := r.readFile(dir)
+		if err != nil {
+			return nil, err
+		}
+		if f != nil {
+			chain = append(chain, f)
+			if f.root {
+				break
+			}
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	slashPath := filepath.ToSlash(absPath)
+	props := Properties{}
+	for i := len(chain) - 1; i >= 0; i-- {
+		for _, s := range chain[i].sections {
+			if !s.pattern.match(slashPath) {
+				continue
+			}
+			for _, prop := range s.properties {
+				props[prop[0]] = prop[1]
+			}
+		}
+	}
+	for key, value := range props {
+		if value == "unset" {
+			delete(props, key)
+		}
+	}
+	return props, nil
+}
+
+func (r *Resolver) readFile(dir string) (*file, error) {
+	if f, ok := r.files[dir]; ok {
+		return f, nil
+	}
+	content, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			r.files[dir] = nil
+			return nil, nil
+		}
+		return nil, err
+	}
+	f, err := parse(content, filepath.ToSlash(dir))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Join(dir, FileName), err)
+	}
+	r.files[dir] = f
+	return f, nil
+}
+
+func parse(content []byte, dir string) (*file, error) {
+	f := &file{}
+	var current *section
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if lineNum == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unterminated section header", lineNum)
+			}
+			pattern, err := compileSectionGlob(line[1:len(line)-1], dir)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			f.sections = append(f.sections, section{pattern: pattern})
+			current = &f.sections[len(f.sections)-1]
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected a key = value pair", lineNum)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.ToLower(strings.TrimSpace(value))
+		if current == nil {
+			// Only the root property is allowed in the preamble.
+			if key == "root" {
+				f.root = value == "true"
+			}
+			continue
+		}
+		current.properties = append(current.properties, [2]string{key, value})
+	}
+	return f, scanner.Err()
+}
+
+// glob is an EditorConfig section pattern compiled to a regular expression.
+// Numeric ranges like {1..3} match any integer and are checked separately.
+type glob struct {
+	re     *regexp.Regexp
+	ranges [][2]int
+}
+
+func (g *glob) match(path string) bool {
+	submatches := g.re.FindStringSubmatch(path)
+	if submatches == nil {
+		return false
+	}
+	for i, r := range g.ranges {
+		// A range in an alternative that didn't match is empty.
+		if submatches[i+1] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(submatches[i+1])
+		if err != nil {
+			return false
+		}
+		if n < r[0] || n > r[1] {
+			return false
+		}
+	}
+	return true
+}
+
+var numericRange = regexp.MustCompile(`^([+-]?\d+)\.\.([+-]?\d+)$`)
+
+// compileSectionGlob compiles a section pattern relative to dir. A pattern
+// without a slash matches file names at any depth, while a pattern with a
+// slash is anchored to dir.
+func compileSectionGlob(pattern string, dir string) (*glob, error) {
+	prefix := regexp.QuoteMeta(strings.TrimSuffix(dir, "/") + "/")
+	if strings.Contains(pattern, "/") {
+		pattern = strings.TrimPrefix(pattern, "/")
+	} else {
+		prefix += `(?:.*/)?`
+	}
+	g := &glob{}
+	re, err := regexp.Compile("^" + prefix + g.translate(pattern) + "$")
+	if err != nil {
+		return nil, err
+	}
+	g.re = re
+	return g, nil
+}
+
+// translate converts the glob syntax in pattern to regular expression
+// syntax.
+func (g *glob) translate(pattern string) string {
+	var sb strings.Builder
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch c {
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(string(runes[i])))
+			} else {
+				sb.WriteString(`\\`)
+			}
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				i++
+				if i+1 < len(runes) && runes[i+1] == '/' {
+					// **/ also matches no directories at all.
+					i++
+					sb.WriteString(`(?:.*/)?`)
+				} else {
+					sb.WriteString(`.*`)
+				}
+			} else {
+				sb.WriteString(`[^/]*`)
+			}
+		case '?':
+			sb.WriteString(`[^/]`)
+		case '[':
+			end := indexClosing(runes, i, '[', ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := string(runes[i+1 : end])
+			if strings.Contains(class, "/") {
+				sb.WriteString(regexp.QuoteMeta(string(runes[i : end+1])))
+			} else {
+				if strings.HasPrefix(class, "!") {
+					class = "^" + class[1:]
+				}
+				sb.WriteString("[" + class + "]")
+			}
+			i = end
+		case '{':
+			end := indexClosing(runes, i, '{', '}')
+			if end < 0 {
+				sb.WriteString(`\{`)
+				continue
+			}
+			inner := string(runes[i+1 : end])
+			if m := numericRange.FindStringSubmatch(inner); m != nil {
+				lo, _ := strconv.Atoi(m[1])
+				hi, _ := strconv.Atoi(m[2])
+				g.ranges = append(g.ranges, [2]int{lo, hi})
+				sb.WriteString(`([+-]?\d+)`)
+			} else if alternatives := splitAlternatives(inner); len(alternatives) > 1 {
+				parts := make([]string, 0, len(alternatives))
+				for _, alt := range alternatives {
+					parts = append(parts, g.translate(alt))
+				}
+				sb.WriteString("(?:" + strings.Join(parts, "|") + ")")
+			} else {
+				// A brace with a single item is taken literally.
+				sb.WriteString(regexp.QuoteMeta("{" + inner + "}"))
+			}
+			i = end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
+
+// indexClosing returns the index of the bracket that closes the one at
+// start, accounting for nesting and escapes, or -1 if there isn't one.
+func indexClosing(runes []rune, start int, open, close rune) int {
+	depth := 0
+	for i := start; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitAlternatives splits the contents of a brace expansion on the
+// commas that are not nested in another brace expansion.
+func splitAlternatives(s string) []string {
+	alternatives := []string{}
+	depth := 0
+	last := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				alternatives = append(alternatives, s[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(alternatives, s[last:])
+}
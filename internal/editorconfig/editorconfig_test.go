@@ -0,0 +1,139 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package editorconfig_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/yamlfmt/internal/assert"
+	"github.com/google/yamlfmt/internal/editorconfig"
+	"github.com/google/yamlfmt/internal/tempfile"
+)
+
+func TestProperties(t *testing.T) {
+	tempDir := t.TempDir()
+	files := tempfile.Paths{
+		{BasePath: tempDir, FilePath: "parent", IsDir: true},
+		{BasePath: tempDir, FilePath: "parent/repo", IsDir: true},
+		{BasePath: tempDir, FilePath: "parent/.editorconfig", Content: []byte(`
+[*]
+indent_size = 8
+`)},
+		{BasePath: tempDir, FilePath: "parent/repo/.editorconfig", Content: []byte(`
+root = true
+
+[*]
+end_of_line = LF
+indent_size = 4
+
+[*.{yaml,yml}]
+indent_size = 2
+insert_final_newline = true
+
+[deploy/**.yaml]
+end_of_line = crlf
+
+[values-{1..3}.yaml]
+max_line_length = 80
+
+[legacy.yaml]
+indent_size = unset
+`)},
+	}
+	assert.NilErr(t, files.CreateAll())
+	repo := filepath.Join(tempDir, "parent", "repo")
+
+	testCases := []struct {
+		name     string
+		path     string
+		expected editorconfig.Properties
+	}{
+		{
+			name: "glob alternatives",
+			path: "a.yaml",
+			expected: editorconfig.Properties{
+				"end_of_line":          "lf",
+				"indent_size":          "2",
+				"insert_final_newline": "true",
+			},
+		},
+		{
+			name: "pattern without slash matches at any depth",
+			path: "sub/dir/a.yml",
+			expected: editorconfig.Properties{
+				"end_of_line":          "lf",
+				"indent_size":          "2",
+				"insert_final_newline": "true",
+			},
+		},
+		{
+			name: "pattern with slash is relative to the file",
+			path: "deploy/prod/a.yaml",
+			expected: editorconfig.Properties{
+				"end_of_line":          "crlf",
+				"indent_size":          "2",
+				"insert_final_newline": "true",
+			},
+		},
+		{
+			name: "numeric range",
+			path: "values-2.yaml",
+			expected: editorconfig.Properties{
+				"end_of_line":          "lf",
+				"indent_size":          "2",
+				"insert_final_newline": "true",
+				"max_line_length":      "80",
+			},
+		},
+		{
+			name: "outside numeric range",
+			path: "values-4.yaml",
+			expected: editorconfig.Properties{
+				"end_of_line":          "lf",
+				"indent_size":          "2",
+				"insert_final_newline": "true",
+			},
+		},
+		{
+			name: "unset",
+			path: "legacy.yaml",
+			expected: editorconfig.Properties{
+				"end_of_line":          "lf",
+				"insert_final_newline": "true",
+			},
+		},
+		{
+			name: "root stops the search",
+			path: "a.txt",
+			expected: editorconfig.Properties{
+				"end_of_line": "lf",
+				"indent_size": "4",
+			},
+		},
+	}
+
+	resolver := editorconfig.NewResolver()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			props, err := resolver.Properties(filepath.Join(repo, tc.path))
+			assert.NilErr(t, err)
+			assert.Equal(t, len(tc.expected), len(props))
+			for key, value := range tc.expected {
+				assert.Equal(t, value, props[key])
+			}
+		})
+	}
+}
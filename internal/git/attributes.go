@@ -0,0 +1,248 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const AttributesFileName = ".gitattributes"
+
+// The values an attribute can have besides a string, following
+// gitattributes(5).
+const (
+	AttributeSet         = "set"
+	AttributeUnset       = "unset"
+	AttributeUnspecified = ""
+)
+
+// attributeLine is a line of an attributes file, with the attributes that
+// it gives the paths its pattern matches.
+type attributeLine struct {
+	pattern    *IgnorePattern
+	attributes map[string]string
+}
+
+// macroAttributes are the attributes that the built in macros stand for.
+var macroAttributes = map[string]map[string]string{
+	"binary": {"diff": AttributeUnset, "merge": AttributeUnset, "text": AttributeUnset},
+}
+
+// Attributes finds the attributes of paths in a work tree, using the
+// attributes file in each directory, `info/attributes` in the git
+// directory and the file set by `core.attributesFile`.
+type Attributes struct {
+	workTree string
+	// The lines of info/attributes, which take precedence over the
+	// attributes files in the work tree, and of core.attributesFile,
+	// which doesn't.
+	infoLines   []attributeLine
+	globalLines []attributeLine
+	dirLines    map[string][]attributeLine
+}
+
+// Attributes reads the attributes files of the repository that don't
+// belong to a directory. The `.gitattributes` files are read as they are
+// needed.
+func (r *Repo) Attributes() (*Attributes, error) {
+	attributes := &Attributes{workTree: r.WorkTree, dirLines: map[string][]attributeLine{}}
+	var err error
+	attributes.infoLines, err = readAttributesFile(filepath.Join(r.CommonDir, "info", "attributes"))
+	if err != nil {
+		return nil, err
+	}
+	attributesFile := expandHome(r.config["core.attributesfile"])
+	if attributesFile == "" {
+		if configHome := xdgConfigHome(); configHome != "" {
+			attributesFile = filepath.Join(configHome, "git", "attributes")
+		}
+	}
+	if attributesFile != "" {
+		attributes.globalLines, err = readAttributesFile(attributesFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return attributes, nil
+}
+
+// readAttributesFile reads the lines of an attributes file that applies
+// to the whole work tree, which may not exist.
+func readAttributesFile(attributesPath string) ([]attributeLine, error) {
+	content, err := os.ReadFile(attributesPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return parseAttributesFile(content, attributesPath, ""), nil
+}
+
+func parseAttributesFile(content []byte, source string, base string) []attributeLine {
+	lines := []attributeLine{}
+	for i, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(strings.TrimSuffix(line, "\r"))
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		pattern := parseIgnoreLine(fields[0])
+		// Negative patterns aren't allowed in attributes files.
+		if pattern == nil || pattern.Negate {
+			continue
+		}
+		pattern.Source = source
+		pattern.LineNo = i + 1
+		pattern.Line = line
+		pattern.base = base
+		attributes := map[string]string{}
+		for _, field := range fields[1:] {
+			switch {
+			case strings.HasPrefix(field, "-"):
+				attributes[field[1:]] = AttributeUnset
+			case strings.HasPrefix(field, "!"):
+				attributes[field[1:]] = AttributeUnspecified
+			case strings.Contains(field, "="):
+				name, value, _ := strings.Cut(field, "=")
+				attributes[name] = value
+			default:
+				attributes[field] = AttributeSet
+				for name, value := range macroAttributes[field] {
+					attributes[name] = value
+				}
+			}
+		}
+		lines = append(lines, attributeLine{pattern: pattern, attributes: attributes})
+	}
+	return lines
+}
+
+// Get returns the value of the attribute name for the slash separated
+// relPath, which is AttributeUnspecified if no line sets it.
+func (a *Attributes) Get(relPath string, name string) (string, error) {
+	levels := [][]attributeLine{a.infoLines}
+	for dir := path.Dir(relPath); ; dir = path.Dir(dir) {
+		lines, err := a.linesForDir(dir)
+		if err != nil {
+			return "", err
+		}
+		levels = append(levels, lines)
+		if dir == "." {
+			break
+		}
+	}
+	levels = append(levels, a.globalLines)
+
+	for _, lines := range levels {
+		for i := len(lines) - 1; i >= 0; i-- {
+			value, ok := lines[i].attributes[name]
+			if ok && lines[i].pattern.matches(relPath, false) {
+				return value, nil
+			}
+		}
+	}
+	return AttributeUnspecified, nil
+}
+
+func (a *Attributes) linesForDir(dir string) ([]attributeLine, error) {
+	if lines, ok := a.dirLines[dir]; ok {
+		return lines, nil
+	}
+	attributesPath := filepath.Join(a.workTree, filepath.FromSlash(dir), AttributesFileName)
+	content, err := os.ReadFile(attributesPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	base := dir
+	if base == "." {
+		base = ""
+	}
+	lines := parseAttributesFile(content, attributesPath, base)
+	a.dirLines[dir] = lines
+	return lines, nil
+}
+
+// CleanContent converts the line endings of content, the content of the
+// work tree file at the slash separated relPath, the way that git does
+// when it adds the file, following the `text` and `eol` attributes and
+// `core.autocrlf`. Clean filters set with the `filter` attribute aren't
+// run. blobID is the blob git has for the file, if any; like git, files
+// that are only normalized because they look like text keep their CRLF
+// line endings if the blob already has them.
+func (r *Repo) CleanContent(relPath string, content []byte, blobID string) ([]byte, error) {
+	if !bytes.Contains(content, []byte("\r\n")) {
+		return content, nil
+	}
+	if r.attributes == nil {
+		attributes, err := r.Attributes()
+		if err != nil {
+			return nil, err
+		}
+		r.attributes = attributes
+	}
+	text, err := r.attributes.Get(relPath, "text")
+	if err != nil {
+		return nil, err
+	}
+	eol, err := r.attributes.Get(relPath, "eol")
+	if err != nil {
+		return nil, err
+	}
+	auto := false
+	switch {
+	case text == AttributeUnset:
+		return content, nil
+	case text == "auto":
+		auto = true
+	case text == AttributeSet || eol == "lf" || eol == "crlf":
+	default:
+		// Without attributes, core.autocrlf decides.
+		autocrlf := strings.ToLower(r.config["core.autocrlf"])
+		if autocrlf != "true" && autocrlf != "input" {
+			return content, nil
+		}
+		auto = true
+	}
+	if auto {
+		if isBinary(content) {
+			return content, nil
+		}
+		if blobID != "" {
+			_, blob, err := r.ReadObject(blobID)
+			if err != nil {
+				return nil, err
+			}
+			if bytes.Contains(blob, []byte("\r")) {
+				return content, nil
+			}
+		}
+	}
+	return bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n")), nil
+}
+
+// isBinary reports whether content looks binary to git's automatic line
+// ending conversion, which is when it has a NUL byte or a lone CR.
+func isBinary(content []byte) bool {
+	if bytes.IndexByte(content, 0) >= 0 {
+		return true
+	}
+	for i, ch := range content {
+		if ch == '\r' && (i+1 == len(content) || content[i+1] != '\n') {
+			return true
+		}
+	}
+	return false
+}
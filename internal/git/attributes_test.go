@@ -0,0 +1,62 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/yamlfmt/internal/assert"
+	"github.com/google/yamlfmt/internal/tempfile"
+)
+
+func TestAttributes(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	tempDir := t.TempDir()
+	files := tempfile.Paths{
+		{BasePath: tempDir, FilePath: ".git", IsDir: true},
+		{BasePath: tempDir, FilePath: ".git/info", IsDir: true},
+		{BasePath: tempDir, FilePath: ".git/info/attributes", Content: []byte("info.yaml -text\n")},
+		{BasePath: tempDir, FilePath: ".gitattributes", Content: []byte("* text=auto\n*.png binary\ndocs/ -text\ninfo.yaml text\n")},
+		{BasePath: tempDir, FilePath: "sub", IsDir: true},
+		{BasePath: tempDir, FilePath: "sub/.gitattributes", Content: []byte("*.yaml eol=crlf\nreset.yaml !text\n")},
+	}
+	assert.NilErr(t, files.CreateAll())
+	repo := &Repo{WorkTree: tempDir, GitDir: filepath.Join(tempDir, ".git"), CommonDir: filepath.Join(tempDir, ".git"), config: gitConfig{}}
+	attributes, err := repo.Attributes()
+	assert.NilErr(t, err)
+
+	testCases := []struct {
+		path     string
+		name     string
+		expected string
+	}{
+		{path: "x.yaml", name: "text", expected: "auto"},
+		{path: "x.yaml", name: "eol", expected: AttributeUnspecified},
+		{path: "a/x.png", name: "text", expected: AttributeUnset},
+		{path: "a/x.png", name: "binary", expected: AttributeSet},
+		// Directory patterns don't apply to the files inside.
+		{path: "docs/x.yaml", name: "text", expected: "auto"},
+		{path: "info.yaml", name: "text", expected: AttributeUnset},
+		{path: "sub/x.yaml", name: "eol", expected: "crlf"},
+		{path: "sub/x.yaml", name: "text", expected: "auto"},
+		{path: "sub/reset.yaml", name: "text", expected: AttributeUnspecified},
+	}
+	for _, tc := range testCases {
+		value, err := attributes.Get(tc.path, tc.name)
+		assert.NilErr(t, err)
+		assert.Assert(t, value == tc.expected, "expected %s of %s to be %q, got %q", tc.name, tc.path, tc.expected, value)
+	}
+}
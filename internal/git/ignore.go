@@ -0,0 +1,289 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const IgnoreFileName = ".gitignore"
+
+// IgnorePattern is a pattern from an ignore file.
+type IgnorePattern struct {
+	// Source is the path of the ignore file the pattern is from.
+	Source string
+	LineNo int
+	Line   string
+	Negate bool
+
+	dirOnly bool
+	// Patterns without a slash match the name of a path in any
+	// directory, and the rest match the path relative to base.
+	matchName bool
+	base      string
+	re        *regexp.Regexp
+}
+
+func (p *IgnorePattern) matches(relPath string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	if p.base != "" {
+		var ok bool
+		relPath, ok = strings.CutPrefix(relPath, p.base+"/")
+		if !ok {
+			return false
+		}
+	}
+	if p.matchName {
+		return p.re.MatchString(path.Base(relPath))
+	}
+	return p.re.MatchString(relPath)
+}
+
+// ParseIgnoreFile parses the patterns in content, which is read from the
+// ignore file at source. The patterns apply to paths in base, which is
+// slash separated and relative to the work tree.
+func ParseIgnoreFile(content []byte, source string, base string) []*IgnorePattern {
+	patterns := []*IgnorePattern{}
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		pattern := parseIgnoreLine(line)
+		if pattern == nil {
+			continue
+		}
+		pattern.Source = source
+		pattern.LineNo = i + 1
+		pattern.Line = line
+		pattern.base = base
+		patterns = append(patterns, pattern)
+	}
+	return patterns
+}
+
+func parseIgnoreLine(line string) *IgnorePattern {
+	line = trimTrailingSpaces(line)
+	if line == "" || line[0] == '#' {
+		return nil
+	}
+	pattern := &IgnorePattern{}
+	if line[0] == '!' {
+		pattern.Negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return nil
+	}
+	if !strings.Contains(line, "/") {
+		pattern.matchName = true
+	}
+	line = strings.TrimPrefix(line, "/")
+	re, err := regexp.Compile("^" + translateIgnorePattern(line) + "$")
+	if err != nil {
+		return nil
+	}
+	pattern.re = re
+	return pattern
+}
+
+// trimTrailingSpaces removes trailing spaces that aren't escaped with a
+// backslash.
+func trimTrailingSpaces(line string) string {
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	return line
+}
+
+// translateIgnorePattern translates the wildcards of a gitignore pattern
+// to a regular expression, following gitignore(5).
+func translateIgnorePattern(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		ch := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/") && (i == 0 || pattern[i-1] == '/'):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case pattern[i:] == "**" && i > 0 && pattern[i-1] == '/':
+			b.WriteString(".*")
+			i++
+		case ch == '*':
+			b.WriteString("[^/]*")
+		case ch == '?':
+			b.WriteString("[^/]")
+		case ch == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end == 0 {
+				// A ] right after the [ is part of the set.
+				if next := strings.IndexByte(pattern[i+2:], ']'); next >= 0 {
+					end = next + 1
+				} else {
+					end = -1
+				}
+			}
+			if end < 0 {
+				b.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case ch == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	return b.String()
+}
+
+// Ignorer decides whether paths in a work tree are ignored, using the
+// ignore file in each directory, and for a repository, `info/exclude` in
+// the git directory and the file set by `core.excludesFile`.
+type Ignorer struct {
+	workTree string
+	fileName string
+	// The patterns of info/exclude and core.excludesFile, in order of
+	// precedence.
+	repoPatterns [][]*IgnorePattern
+	dirPatterns  map[string][]*IgnorePattern
+}
+
+// NewIgnorer returns an Ignorer that only uses the files named fileName in
+// root and the directories below it, which use the gitignore syntax.
+func NewIgnorer(root string, fileName string) *Ignorer {
+	return &Ignorer{
+		workTree:    root,
+		fileName:    fileName,
+		dirPatterns: map[string][]*IgnorePattern{},
+	}
+}
+
+// Ignorer reads the ignore files of the repository that don't belong to a
+// directory. The `.gitignore` files are read as they are needed.
+func (r *Repo) Ignorer() (*Ignorer, error) {
+	ignorer := NewIgnorer(r.WorkTree, IgnoreFileName)
+	excludesFile := expandHome(r.config["core.excludesfile"])
+	if excludesFile == "" {
+		if configHome := xdgConfigHome(); configHome != "" {
+			excludesFile = filepath.Join(configHome, "git", "ignore")
+		}
+	}
+	for _, ignorePath := range []string{filepath.Join(r.CommonDir, "info", "exclude"), excludesFile} {
+		if ignorePath == "" {
+			continue
+		}
+		content, err := os.ReadFile(ignorePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		ignorer.repoPatterns = append(ignorer.repoPatterns, ParseIgnoreFile(content, ignorePath, ""))
+	}
+	return ignorer, nil
+}
+
+// Match returns the pattern that decides whether the slash separated
+// relPath is ignored, or nil if no pattern matches it. The path is ignored
+// if the pattern isn't negated. Only the path itself is matched, so a
+// caller that walks the work tree should stop at ignored directories,
+// since git doesn't look inside of them.
+func (ig *Ignorer) Match(relPath string, isDir bool) (*IgnorePattern, error) {
+	dirs := []string{}
+	for dir := path.Dir(relPath); ; dir = path.Dir(dir) {
+		dirs = append(dirs, dir)
+		if dir == "." {
+			break
+		}
+	}
+	levels := [][]*IgnorePattern{}
+	for _, dir := range dirs {
+		patterns, err := ig.patternsForDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		levels = append(levels, patterns)
+	}
+	levels = append(levels, ig.repoPatterns...)
+
+	for _, patterns := range levels {
+		for i := len(patterns) - 1; i >= 0; i-- {
+			if patterns[i].matches(relPath, isDir) {
+				return patterns[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// MatchWithParents is like Match, but it also matches each directory that
+// relPath is in, starting from the top. If a directory is ignored, the
+// pattern that ignores it decides, since git doesn't look inside of
+// ignored directories.
+func (ig *Ignorer) MatchWithParents(relPath string, isDir bool) (*IgnorePattern, error) {
+	parts := strings.Split(relPath, "/")
+	for i := 1; i < len(parts); i++ {
+		pattern, err := ig.Match(strings.Join(parts[:i], "/"), true)
+		if err != nil {
+			return nil, err
+		}
+		if pattern != nil && !pattern.Negate {
+			return pattern, nil
+		}
+	}
+	return ig.Match(relPath, isDir)
+}
+
+// IsIgnored reports whether the slash separated relPath is ignored.
+func (ig *Ignorer) IsIgnored(relPath string, isDir bool) (bool, error) {
+	pattern, err := ig.Match(relPath, isDir)
+	if err != nil {
+		return false, err
+	}
+	return pattern != nil && !pattern.Negate, nil
+}
+
+func (ig *Ignorer) patternsForDir(dir string) ([]*IgnorePattern, error) {
+	if patterns, ok := ig.dirPatterns[dir]; ok {
+		return patterns, nil
+	}
+	ignorePath := filepath.Join(ig.workTree, filepath.FromSlash(dir), ig.fileName)
+	content, err := os.ReadFile(ignorePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	base := dir
+	if base == "." {
+		base = ""
+	}
+	patterns := ParseIgnoreFile(content, ignorePath, base)
+	ig.dirPatterns[dir] = patterns
+	return patterns, nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import (
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/google/yamlfmt/internal/assert"
+	"github.com/google/yamlfmt/internal/tempfile"
+)
+
+func TestIgnorePatterns(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		matches bool
+	}{
+		{pattern: "*.yaml", path: "a/b/x.yaml", matches: true},
+		{pattern: "*.yaml", path: "x.yml", matches: false},
+		{pattern: "build/", path: "a/build", isDir: true, matches: true},
+		{pattern: "build/", path: "a/build", isDir: false, matches: false},
+		{pattern: "/build", path: "build", matches: true},
+		{pattern: "/build", path: "a/build", matches: false},
+		{pattern: "a/*.yaml", path: "a/x.yaml", matches: true},
+		{pattern: "a/*.yaml", path: "a/b/x.yaml", matches: false},
+		{pattern: "**/gen", path: "a/b/gen", matches: true},
+		{pattern: "**/gen", path: "gen", matches: true},
+		{pattern: "a/**/x.yaml", path: "a/x.yaml", matches: true},
+		{pattern: "a/**/x.yaml", path: "a/b/c/x.yaml", matches: true},
+		{pattern: "a/**", path: "a/b/c", matches: true},
+		{pattern: "a/**", path: "a", isDir: true, matches: false},
+		{pattern: "x?.yaml", path: "x1.yaml", matches: true},
+		{pattern: "x[0-9].yaml", path: "x1.yaml", matches: true},
+		{pattern: "x[!0-9].yaml", path: "x1.yaml", matches: false},
+		{pattern: `\#x.yaml`, path: "#x.yaml", matches: true},
+		{pattern: "x.yaml   ", path: "x.yaml", matches: true},
+		{pattern: "# comment", path: "# comment", matches: false},
+	}
+	for _, tc := range testCases {
+		patterns := ParseIgnoreFile([]byte(tc.pattern), ".gitignore", "")
+		matches := len(patterns) == 1 && patterns[0].matches(tc.path, tc.isDir)
+		assert.Assert(t, matches == tc.matches, "expected pattern %q matching %q (dir: %v) to be %v", tc.pattern, tc.path, tc.isDir, tc.matches)
+	}
+}
+
+func TestUntrackedFiles(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("HOME", configHome)
+	workTree := t.TempDir()
+	files := tempfile.Paths{
+		{BasePath: configHome, FilePath: "git", IsDir: true},
+		{BasePath: configHome, FilePath: "git/ignore", Content: []byte("*.global.yaml\n")},
+		{BasePath: workTree, FilePath: ".git", IsDir: true},
+		{BasePath: workTree, FilePath: ".git/info", IsDir: true},
+		{BasePath: workTree, FilePath: ".git/info/exclude", Content: []byte("local.yaml\n")},
+		{BasePath: workTree, FilePath: ".git/index", Content: makeIndex(2, []testEntry{{path: ".gitignore"}, {path: "vendor/tracked.yaml"}})},
+		{BasePath: workTree, FilePath: ".gitignore", Content: []byte("vendor/\n*.log\n!keep.log\n")},
+		{BasePath: workTree, FilePath: "keep.log"},
+		{BasePath: workTree, FilePath: "x.log"},
+		{BasePath: workTree, FilePath: "local.yaml"},
+		{BasePath: workTree, FilePath: "x.global.yaml"},
+		{BasePath: workTree, FilePath: "vendor", IsDir: true},
+		{BasePath: workTree, FilePath: "vendor/tracked.yaml"},
+		{BasePath: workTree, FilePath: "vendor/.gitignore", Content: []byte("!*.yaml\n")},
+		{BasePath: workTree, FilePath: "vendor/untracked.yaml"},
+		{BasePath: workTree, FilePath: "sub", IsDir: true},
+		{BasePath: workTree, FilePath: "sub/.gitignore", Content: []byte("ignored.yaml\n!keep.log\n")},
+		{BasePath: workTree, FilePath: "sub/ignored.yaml"},
+		{BasePath: workTree, FilePath: "sub/x.yaml"},
+		{BasePath: workTree, FilePath: "sub/nested", IsDir: true},
+		{BasePath: workTree, FilePath: "sub/nested/.git", Content: []byte("gitdir: ../../.git/modules/nested\n")},
+		{BasePath: workTree, FilePath: "sub/nested/x.yaml"},
+	}
+	assert.NilErr(t, files.CreateAll())
+
+	repo, err := FindRepo(filepath.Join(workTree, "sub"))
+	assert.NilErr(t, err)
+	assert.Equal(t, workTree, repo.WorkTree)
+	tracked, err := repo.TrackedFiles()
+	assert.NilErr(t, err)
+	untracked, err := repo.UntrackedFiles(tracked)
+	assert.NilErr(t, err)
+	sort.Strings(untracked)
+	// Nothing in vendor is re-included, since git doesn't look inside of
+	// an ignored directory.
+	expected := []string{"keep.log", "sub/.gitignore", "sub/x.yaml"}
+	assert.Assert(t, slices.Equal(expected, untracked), "expected %v, got %v", expected, untracked)
+
+	ignorer, err := repo.Ignorer()
+	assert.NilErr(t, err)
+	pattern, err := ignorer.Match("sub/ignored.yaml", false)
+	assert.NilErr(t, err)
+	assert.Equal(t, filepath.Join(workTree, "sub", ".gitignore"), pattern.Source)
+	assert.Equal(t, 1, pattern.LineNo)
+}
+
+func TestIgnorerMatchWithParents(t *testing.T) {
+	root := t.TempDir()
+	files := tempfile.Paths{
+		{BasePath: root, FilePath: ".yamlfmtignore", Content: []byte("gen/\n")},
+		{BasePath: root, FilePath: "gen", IsDir: true},
+		{BasePath: root, FilePath: "gen/.yamlfmtignore", Content: []byte("!keep.yaml\n")},
+		{BasePath: root, FilePath: "sub", IsDir: true},
+		{BasePath: root, FilePath: "sub/.yamlfmtignore", Content: []byte("*.yaml\n!keep.yaml\n")},
+		{BasePath: root, FilePath: "sub/.gitignore", Content: []byte("keep.yaml\n")},
+	}
+	assert.NilErr(t, files.CreateAll())
+
+	testCases := []struct {
+		path    string
+		ignored bool
+	}{
+		{path: "x.yaml", ignored: false},
+		{path: "sub/x.yaml", ignored: true},
+		{path: "sub/keep.yaml", ignored: false},
+		// Files in an ignored directory can't be re-included.
+		{path: "gen/keep.yaml", ignored: true},
+	}
+	ignorer := NewIgnorer(root, ".yamlfmtignore")
+	for _, tc := range testCases {
+		pattern, err := ignorer.MatchWithParents(tc.path, false)
+		assert.NilErr(t, err)
+		ignored := pattern != nil && !pattern.Negate
+		assert.Assert(t, ignored == tc.ignored, "expected %s to be ignored: %v, got pattern %+v", tc.path, tc.ignored, pattern)
+	}
+}
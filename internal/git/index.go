@@ -0,0 +1,175 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var ErrBadIndex = errors.New("bad git index")
+
+const (
+	indexSignature = "DIRC"
+	// The ctime, mtime, dev, ino, mode, uid, gid and size fields that
+	// each entry starts with.
+	indexEntryStatSize = 40
+
+	flagExtended   = 0x4000
+	flagStageMask  = 0x3000
+	flagNameMask   = 0x0fff
+	flagSkipWorkTr = 0x4000 // In the extended flags.
+
+	modeTypeMask = 0o170000
+	modeTypeTree = 0o040000
+	modeTypeLink = 0o160000
+)
+
+// TrackedFiles returns the slash separated paths, relative to the work
+// tree, of the files in the index. Submodules, and files that aren't
+// checked out in a sparse checkout, are left out.
+func (r *Repo) TrackedFiles() ([]string, error) {
+	content, err := os.ReadFile(filepath.Join(r.GitDir, "index"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			// A new repository has no index until something is added.
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	return parseIndex(content, r.hashSize())
+}
+
+// parseIndex reads the paths from an index file in any of the versions
+// 2 to 4 described in gitformat-index(5).
+func parseIndex(content []byte, hashSize int) ([]string, error) {
+	if len(content) < 12 || string(content[:4]) != indexSignature {
+		return nil, fmt.Errorf("%w: missing signature", ErrBadIndex)
+	}
+	version := binary.BigEndian.Uint32(content[4:8])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrBadIndex, version)
+	}
+	count := binary.BigEndian.Uint32(content[8:12])
+
+	paths := []string{}
+	offset := 12
+	prevPath := []byte{}
+	for i := uint32(0); i < count; i++ {
+		start := offset
+		fixedSize := indexEntryStatSize + hashSize + 2
+		if offset+fixedSize > len(content) {
+			return nil, fmt.Errorf("%w: entry %d is truncated", ErrBadIndex, i)
+		}
+		mode := binary.BigEndian.Uint32(content[offset+24 : offset+28])
+		flags := binary.BigEndian.Uint16(content[offset+indexEntryStatSize+hashSize:])
+		offset += fixedSize
+		skipWorkTree := false
+		if flags&flagExtended != 0 {
+			if version < 3 || offset+2 > len(content) {
+				return nil, fmt.Errorf("%w: entry %d has bad extended flags", ErrBadIndex, i)
+			}
+			skipWorkTree = binary.BigEndian.Uint16(content[offset:])&flagSkipWorkTr != 0
+			offset += 2
+		}
+
+		var path []byte
+		if version == 4 {
+			strip, n := readOffsetVarint(content[offset:])
+			if n == 0 || int(strip) > len(prevPath) {
+				return nil, fmt.Errorf("%w: entry %d has a bad path prefix", ErrBadIndex, i)
+			}
+			offset += n
+			end := bytes.IndexByte(content[offset:], 0)
+			if end < 0 {
+				return nil, fmt.Errorf("%w: entry %d is truncated", ErrBadIndex, i)
+			}
+			path = append(bytes.Clone(prevPath[:len(prevPath)-int(strip)]), content[offset:offset+end]...)
+			offset += end + 1
+		} else {
+			nameLen := int(flags & flagNameMask)
+			end := bytes.IndexByte(content[offset:], 0)
+			if nameLen < flagNameMask && offset+nameLen <= len(content) {
+				end = nameLen
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("%w: entry %d is truncated", ErrBadIndex, i)
+			}
+			path = content[offset : offset+end]
+			// Entries are padded with 1 to 8 NUL bytes to a multiple of 8.
+			offset = start + (offset+end-start+8)&^7
+		}
+		prevPath = path
+
+		// Conflicted files have an entry for each stage, so only the
+		// first one is kept.
+		stage := flags & flagStageMask
+		if stage > 0x1000 && len(paths) > 0 && paths[len(paths)-1] == string(path) {
+			continue
+		}
+		if skipWorkTree || mode&modeTypeMask == modeTypeTree || mode&modeTypeMask == modeTypeLink {
+			continue
+		}
+		paths = append(paths, string(path))
+	}
+
+	if err := checkIndexExtensions(content[offset:], hashSize); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// checkIndexExtensions returns an error for extensions that change which
+// entries are in the index, which aren't supported.
+func checkIndexExtensions(content []byte, hashSize int) error {
+	for len(content) > hashSize {
+		if len(content) < 8 {
+			return fmt.Errorf("%w: truncated extension", ErrBadIndex)
+		}
+		signature := string(content[:4])
+		size := int(binary.BigEndian.Uint32(content[4:8]))
+		if signature == "link" {
+			return fmt.Errorf("%w: split index is not supported", ErrBadIndex)
+		}
+		if 8+size > len(content) {
+			return fmt.Errorf("%w: truncated extension %q", ErrBadIndex, signature)
+		}
+		content = content[8+size:]
+	}
+	return nil
+}
+
+// readOffsetVarint reads the variable length integer that index version
+// 4 uses for path prefixes, returning the value and the number of bytes
+// read, or 0 bytes if it is truncated.
+func readOffsetVarint(b []byte) (uint64, int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+	value := uint64(b[0] & 0x7f)
+	n := 1
+	for b[n-1]&0x80 != 0 {
+		if n >= len(b) {
+			return 0, 0
+		}
+		value = ((value + 1) << 7) | uint64(b[n]&0x7f)
+		n++
+	}
+	return value, n
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/yamlfmt/internal/assert"
+)
+
+type testEntry struct {
+	path  string
+	mode  uint32
+	stage uint16
+	// Only written in version 3 and up.
+	skipWorkTree bool
+}
+
+// makeIndex writes entries in the index format of the given version,
+// with zeroed stat data and hashes.
+func makeIndex(version uint32, entries []testEntry) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(indexSignature)
+	binary.Write(&buf, binary.BigEndian, version)
+	binary.Write(&buf, binary.BigEndian, uint32(len(entries)))
+	prevPath := ""
+	for _, entry := range entries {
+		start := buf.Len()
+		stat := make([]byte, indexEntryStatSize)
+		mode := entry.mode
+		if mode == 0 {
+			mode = 0o100644
+		}
+		binary.BigEndian.PutUint32(stat[24:], mode)
+		buf.Write(stat)
+		buf.Write(make([]byte, 20))
+		flags := entry.stage<<12 | uint16(min(len(entry.path), flagNameMask))
+		if entry.skipWorkTree {
+			flags |= flagExtended
+		}
+		binary.Write(&buf, binary.BigEndian, flags)
+		if entry.skipWorkTree {
+			binary.Write(&buf, binary.BigEndian, uint16(flagSkipWorkTr))
+		}
+		if version == 4 {
+			common := 0
+			for common < len(prevPath) && common < len(entry.path) && prevPath[common] == entry.path[common] {
+				common++
+			}
+			// Every strip length in the tests fits in a single byte.
+			buf.WriteByte(byte(len(prevPath) - common))
+			buf.WriteString(entry.path[common:])
+			buf.WriteByte(0)
+		} else {
+			buf.WriteString(entry.path)
+			size := buf.Len() - start
+			buf.Write(make([]byte, (size+8)&^7-size))
+		}
+		prevPath = entry.path
+	}
+	buf.Write(make([]byte, 20))
+	return buf.Bytes()
+}
+
+func TestParseIndex(t *testing.T) {
+	entries := []testEntry{
+		{path: ".gitignore"},
+		{path: "charts/app/values.yaml"},
+		{path: "charts/app/values.yml"},
+		{path: "conflict.yaml", stage: 1},
+		{path: "conflict.yaml", stage: 2},
+		{path: "conflict.yaml", stage: 3},
+		{path: "submodule", mode: 0o160000},
+		{path: "x.yaml"},
+	}
+	expected := []string{".gitignore", "charts/app/values.yaml", "charts/app/values.yml", "conflict.yaml", "x.yaml"}
+	for _, version := range []uint32{2, 3, 4} {
+		paths, err := parseIndex(makeIndex(version, entries), 20)
+		assert.NilErr(t, err)
+		assert.Assert(t, slices.Equal(expected, paths), "version %d: expected %v, got %v", version, expected, paths)
+	}
+}
+
+func TestParseIndexSkipWorkTree(t *testing.T) {
+	paths, err := parseIndex(makeIndex(3, []testEntry{
+		{path: "a.yaml"},
+		{path: "sparse/b.yaml", skipWorkTree: true},
+	}), 20)
+	assert.NilErr(t, err)
+	assert.Assert(t, slices.Equal([]string{"a.yaml"}, paths), "expected skip-worktree entries to be left out, got %v", paths)
+}
+
+func TestParseIndexErrors(t *testing.T) {
+	testCases := map[string][]byte{
+		"no signature":        []byte("not an index"),
+		"unsupported version": makeIndex(5, nil),
+		"truncated":           makeIndex(2, []testEntry{{path: "a.yaml"}})[:40],
+	}
+	for name, content := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseIndex(content, 20)
+			assert.Assert(t, errors.Is(err, ErrBadIndex), "expected ErrBadIndex, got %v", err)
+		})
+	}
+}
@@ -0,0 +1,449 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrObjectNotFound = errors.New("git object not found")
+	ErrBadObject      = errors.New("bad git object")
+)
+
+type ObjectType string
+
+const (
+	ObjectCommit ObjectType = "commit"
+	ObjectTree   ObjectType = "tree"
+	ObjectBlob   ObjectType = "blob"
+	ObjectTag    ObjectType = "tag"
+)
+
+// The object types as they are numbered in pack files.
+const (
+	packCommit   = 1
+	packTree     = 2
+	packBlob     = 3
+	packTag      = 4
+	packOfsDelta = 6
+	packRefDelta = 7
+)
+
+var packObjectTypes = map[int]ObjectType{
+	packCommit: ObjectCommit,
+	packTree:   ObjectTree,
+	packBlob:   ObjectBlob,
+	packTag:    ObjectTag,
+}
+
+// objectStore reads objects from the loose object directories and pack
+// files of a repository and its alternates.
+type objectStore struct {
+	hashSize int
+	dirs     []string
+	packs    []*pack
+	loaded   bool
+}
+
+func (r *Repo) objects() (*objectStore, error) {
+	if r.store != nil {
+		return r.store, nil
+	}
+	store := &objectStore{hashSize: r.hashSize()}
+	dirs := []string{filepath.Join(r.CommonDir, "objects")}
+	for i := 0; i < len(dirs); i++ {
+		alternates, err := os.ReadFile(filepath.Join(dirs[i], "info", "alternates"))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(alternates), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || line[0] == '#' {
+				continue
+			}
+			if !filepath.IsAbs(line) {
+				line = filepath.Join(dirs[i], line)
+			}
+			dirs = append(dirs, filepath.Clean(line))
+		}
+	}
+	store.dirs = dirs
+	for _, dir := range dirs {
+		idxPaths, err := filepath.Glob(filepath.Join(dir, "pack", "*.idx"))
+		if err != nil {
+			return nil, err
+		}
+		for _, idxPath := range idxPaths {
+			p, err := openPack(idxPath, store.hashSize)
+			if err != nil {
+				return nil, err
+			}
+			store.packs = append(store.packs, p)
+		}
+	}
+	r.store = store
+	return store, nil
+}
+
+func (r *Repo) hashSize() int {
+	if r.config["extensions.objectformat"] == "sha256" {
+		return sha256.Size
+	}
+	return sha1.Size
+}
+
+func (r *Repo) newHash() hash.Hash {
+	if r.hashSize() == sha256.Size {
+		return sha256.New()
+	}
+	return sha1.New()
+}
+
+// HashBlob returns the hex hash that content has as a blob object.
+func (r *Repo) HashBlob(content []byte) string {
+	h := r.newHash()
+	fmt.Fprintf(h, "%s %d\x00", ObjectBlob, len(content))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ReadObject returns the type and content of the object with the given
+// hex hash.
+func (r *Repo) ReadObject(id string) (ObjectType, []byte, error) {
+	store, err := r.objects()
+	if err != nil {
+		return "", nil, err
+	}
+	return store.read(id)
+}
+
+func (s *objectStore) read(id string) (ObjectType, []byte, error) {
+	if len(id) != s.hashSize*2 {
+		return "", nil, fmt.Errorf("%w: %q", ErrObjectNotFound, id)
+	}
+	for _, dir := range s.dirs {
+		objectType, content, err := readLooseObject(filepath.Join(dir, id[:2], id[2:]))
+		if err == nil {
+			return objectType, content, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", nil, fmt.Errorf("%s: %w", id, err)
+		}
+	}
+	rawID, err := hex.DecodeString(id)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %q", ErrObjectNotFound, id)
+	}
+	for _, p := range s.packs {
+		if offset, ok := p.find(rawID); ok {
+			return p.readAt(offset, s)
+		}
+	}
+	return "", nil, fmt.Errorf("%w: %s", ErrObjectNotFound, id)
+}
+
+// expand returns the full hex hashes of the objects that start with the
+// abbreviated hex hash prefix.
+func (s *objectStore) expand(prefix string) ([]string, error) {
+	matches := map[string]struct{}{}
+	if len(prefix) >= 2 {
+		for _, dir := range s.dirs {
+			entries, err := os.ReadDir(filepath.Join(dir, prefix[:2]))
+			if err != nil {
+				continue
+			}
+			for _, entry := range entries {
+				if strings.HasPrefix(entry.Name(), prefix[2:]) {
+					matches[prefix[:2]+entry.Name()] = struct{}{}
+				}
+			}
+		}
+	}
+	for _, p := range s.packs {
+		for _, id := range p.withPrefix(prefix) {
+			matches[id] = struct{}{}
+		}
+	}
+	ids := []string{}
+	for id := range matches {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func readLooseObject(path string) (ObjectType, []byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+	zr, err := zlib.NewReader(f)
+	if err != nil {
+		return "", nil, err
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return "", nil, err
+	}
+	header, content, ok := bytes.Cut(data, []byte{0})
+	if !ok {
+		return "", nil, ErrBadObject
+	}
+	objectType, size, ok := strings.Cut(string(header), " ")
+	if !ok || strconv.Itoa(len(content)) != size {
+		return "", nil, ErrBadObject
+	}
+	return ObjectType(objectType), content, nil
+}
+
+// pack is a pack file and its version 2 index, as described in
+// gitformat-pack(5).
+type pack struct {
+	path     string
+	hashSize int
+	fanout   [256]uint32
+	ids      []byte
+	offsets  []byte
+	large    []byte
+}
+
+func openPack(idxPath string, hashSize int) (*pack, error) {
+	idx, err := os.ReadFile(idxPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(idx) < 8+256*4 || !bytes.Equal(idx[:4], []byte("\xfftOc")) || binary.BigEndian.Uint32(idx[4:8]) != 2 {
+		return nil, fmt.Errorf("%s: unsupported pack index", idxPath)
+	}
+	p := &pack{path: strings.TrimSuffix(idxPath, ".idx") + ".pack", hashSize: hashSize}
+	for i := range p.fanout {
+		p.fanout[i] = binary.BigEndian.Uint32(idx[8+i*4:])
+	}
+	count := int(p.fanout[255])
+	start := 8 + 256*4
+	idsEnd := start + count*hashSize
+	// The ids are followed by a CRC32 of each object.
+	offsetsStart := idsEnd + count*4
+	offsetsEnd := offsetsStart + count*4
+	if len(idx) < offsetsEnd {
+		return nil, fmt.Errorf("%s: truncated pack index", idxPath)
+	}
+	p.ids = idx[start:idsEnd]
+	p.offsets = idx[offsetsStart:offsetsEnd]
+	p.large = idx[offsetsEnd:]
+	return p, nil
+}
+
+func (p *pack) id(i int) []byte {
+	return p.ids[i*p.hashSize : (i+1)*p.hashSize]
+}
+
+func (p *pack) find(id []byte) (int64, bool) {
+	lo := 0
+	if id[0] > 0 {
+		lo = int(p.fanout[id[0]-1])
+	}
+	hi := int(p.fanout[id[0]])
+	i := lo + sort.Search(hi-lo, func(i int) bool {
+		return bytes.Compare(p.id(lo+i), id) >= 0
+	})
+	if i >= hi || !bytes.Equal(p.id(i), id) {
+		return 0, false
+	}
+	offset := int64(binary.BigEndian.Uint32(p.offsets[i*4:]))
+	if offset&0x80000000 != 0 {
+		largeIdx := int(offset & 0x7fffffff)
+		if len(p.large) < (largeIdx+1)*8 {
+			return 0, false
+		}
+		offset = int64(binary.BigEndian.Uint64(p.large[largeIdx*8:]))
+	}
+	return offset, true
+}
+
+func (p *pack) withPrefix(prefix string) []string {
+	ids := []string{}
+	for i := 0; i < int(p.fanout[255]); i++ {
+		if id := hex.EncodeToString(p.id(i)); strings.HasPrefix(id, prefix) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// readAt reads the object at offset in the pack file, applying deltas to
+// their base objects.
+func (p *pack) readAt(offset int64, store *objectStore) (ObjectType, []byte, error) {
+	f, err := os.Open(p.path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+	return p.readObjectAt(f, offset, store)
+}
+
+func (p *pack) readObjectAt(f *os.File, offset int64, store *objectStore) (ObjectType, []byte, error) {
+	r := bufio.NewReader(io.NewSectionReader(f, offset, 1<<62))
+	b, err := r.ReadByte()
+	if err != nil {
+		return "", nil, err
+	}
+	packType := int(b>>4) & 7
+	size := uint64(b & 0x0f)
+	for shift := 4; b&0x80 != 0; shift += 7 {
+		if b, err = r.ReadByte(); err != nil {
+			return "", nil, err
+		}
+		size |= uint64(b&0x7f) << shift
+	}
+
+	var baseType ObjectType
+	var base []byte
+	switch packType {
+	case packOfsDelta:
+		b, err := r.ReadByte()
+		if err != nil {
+			return "", nil, err
+		}
+		baseOffset := int64(b & 0x7f)
+		for b&0x80 != 0 {
+			if b, err = r.ReadByte(); err != nil {
+				return "", nil, err
+			}
+			baseOffset = ((baseOffset + 1) << 7) | int64(b&0x7f)
+		}
+		baseType, base, err = p.readObjectAt(f, offset-baseOffset, store)
+		if err != nil {
+			return "", nil, err
+		}
+	case packRefDelta:
+		baseID := make([]byte, p.hashSize)
+		if _, err := io.ReadFull(r, baseID); err != nil {
+			return "", nil, err
+		}
+		baseType, base, err = store.read(hex.EncodeToString(baseID))
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return "", nil, err
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return "", nil, err
+	}
+	if uint64(len(data)) != size {
+		return "", nil, fmt.Errorf("%w: object at %d in %s has the wrong size", ErrBadObject, offset, p.path)
+	}
+	if base != nil {
+		data, err = applyDelta(base, data)
+		return baseType, data, err
+	}
+	objectType, ok := packObjectTypes[packType]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: unknown type %d at %d in %s", ErrBadObject, packType, offset, p.path)
+	}
+	return objectType, data, nil
+}
+
+// applyDelta builds an object from the copy and insert instructions of
+// a delta against base.
+func applyDelta(base []byte, delta []byte) ([]byte, error) {
+	readSize := func() (uint64, bool) {
+		size := uint64(0)
+		for shift := 0; len(delta) > 0; shift += 7 {
+			b := delta[0]
+			delta = delta[1:]
+			size |= uint64(b&0x7f) << shift
+			if b&0x80 == 0 {
+				return size, true
+			}
+		}
+		return 0, false
+	}
+	baseSize, ok := readSize()
+	if !ok || baseSize != uint64(len(base)) {
+		return nil, fmt.Errorf("%w: delta base size doesn't match", ErrBadObject)
+	}
+	resultSize, ok := readSize()
+	if !ok {
+		return nil, fmt.Errorf("%w: truncated delta", ErrBadObject)
+	}
+	result := make([]byte, 0, resultSize)
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+		if op&0x80 == 0 {
+			// Insert the next op bytes of the delta.
+			n := int(op)
+			if n == 0 || n > len(delta) {
+				return nil, fmt.Errorf("%w: bad delta insert", ErrBadObject)
+			}
+			result = append(result, delta[:n]...)
+			delta = delta[n:]
+			continue
+		}
+		// Copy from the base, with the offset and size bytes that are
+		// present given by the low bits of op.
+		var copyOffset, copySize uint64
+		for i := 0; i < 7; i++ {
+			if op&(1<<i) == 0 {
+				continue
+			}
+			if len(delta) == 0 {
+				return nil, fmt.Errorf("%w: truncated delta", ErrBadObject)
+			}
+			if i < 4 {
+				copyOffset |= uint64(delta[0]) << (8 * i)
+			} else {
+				copySize |= uint64(delta[0]) << (8 * (i - 4))
+			}
+			delta = delta[1:]
+		}
+		if copySize == 0 {
+			copySize = 0x10000
+		}
+		if copyOffset+copySize > uint64(len(base)) {
+			return nil, fmt.Errorf("%w: delta copy out of range", ErrBadObject)
+		}
+		result = append(result, base[copyOffset:copyOffset+copySize]...)
+	}
+	if uint64(len(result)) != resultSize {
+		return nil, fmt.Errorf("%w: delta result size doesn't match", ErrBadObject)
+	}
+	return result, nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/yamlfmt/internal/assert"
+)
+
+func TestApplyDelta(t *testing.T) {
+	base := []byte("key: value\nother: 1\n")
+	delta := []byte{
+		byte(len(base)), // The base size.
+		23,              // The result size.
+		0x90, 11,        // Copy 11 bytes from offset 0.
+		4, 'n', 'e', 'w', ':', // Insert 4 bytes.
+		0x91, 17, 3, // Copy 3 bytes from offset 17.
+		5, 'x', ':', ' ', '2', '\n', // Insert 5 bytes.
+	}
+	result, err := applyDelta(base, delta)
+	assert.NilErr(t, err)
+	assert.Equal(t, "key: value\nnew: 1\nx: 2\n", string(result))
+}
+
+func TestApplyDeltaErrors(t *testing.T) {
+	base := []byte("key: value\n")
+	testCases := map[string][]byte{
+		"wrong base size":    {3, 1, 1, 'a'},
+		"copy out of range":  {byte(len(base)), 20, 0x90, 20},
+		"wrong result size":  {byte(len(base)), 5, 1, 'a'},
+		"truncated insert":   {byte(len(base)), 5, 5, 'a'},
+		"insert of no bytes": {byte(len(base)), 0, 0},
+	}
+	for name, delta := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := applyDelta(base, delta)
+			assert.Assert(t, errors.Is(err, ErrBadObject), "expected ErrBadObject, got %v", err)
+		})
+	}
+}
+
+func TestObjectHeaders(t *testing.T) {
+	commit := []byte("tree abc\nparent one\nparent two\nauthor a\n\nparent in the message\n")
+	assert.Equal(t, "abc", objectHeader(commit, "tree"))
+	assert.SliceEqual(t, []string{"one", "two"}, objectHeaders(commit, "parent"))
+	assert.Equal(t, "", objectHeader(commit, "object"))
+}
@@ -0,0 +1,265 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package git reads the files that make up a git repository straight from
+// the `.git` directory, so that the git binary isn't needed. It supports
+// the index and the ignore files, which is enough to list the files that
+// git considers part of the repository, and reading commits and trees
+// from the object database.
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrNotARepo = errors.New("not in a git repository")
+
+// Repo is a git repository on disk.
+type Repo struct {
+	// WorkTree is the absolute path of the working tree.
+	WorkTree string
+	// GitDir is the absolute path of the git directory of the working
+	// tree, which holds its index.
+	GitDir string
+	// CommonDir is the absolute path of the git directory that holds the
+	// config and info files. It differs from GitDir for linked worktrees.
+	CommonDir string
+
+	config     gitConfig
+	store      *objectStore
+	attributes *Attributes
+}
+
+// FindRepo finds the repository that dir is in by searching dir and the
+// directories above it for `.git`.
+func FindRepo(dir string) (*Repo, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for d := absDir; ; d = filepath.Dir(d) {
+		gitPath := filepath.Join(d, ".git")
+		if info, err := os.Stat(gitPath); err == nil {
+			gitDir := gitPath
+			if !info.IsDir() {
+				gitDir, err = readGitFile(gitPath)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return openRepo(d, gitDir)
+		}
+		if filepath.Dir(d) == d {
+			return nil, fmt.Errorf("%w: %s", ErrNotARepo, absDir)
+		}
+	}
+}
+
+// readGitFile reads the `gitdir: <path>` file that worktrees and
+// submodules have in place of a `.git` directory.
+func readGitFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+	if !ok {
+		return "", fmt.Errorf("%s: expected gitdir", path)
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+	return filepath.Clean(gitDir), nil
+}
+
+func openRepo(workTree string, gitDir string) (*Repo, error) {
+	repo := &Repo{WorkTree: workTree, GitDir: gitDir, CommonDir: gitDir}
+	if commonDir, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		repo.CommonDir = strings.TrimSpace(string(commonDir))
+		if !filepath.IsAbs(repo.CommonDir) {
+			repo.CommonDir = filepath.Clean(filepath.Join(gitDir, repo.CommonDir))
+		}
+	}
+
+	repo.config = gitConfig{}
+	for _, path := range append(globalConfigPaths(), filepath.Join(repo.CommonDir, "config")) {
+		if err := repo.config.read(path); err != nil {
+			return nil, err
+		}
+	}
+	return repo, nil
+}
+
+func globalConfigPaths() []string {
+	paths := []string{}
+	if configHome := xdgConfigHome(); configHome != "" {
+		paths = append(paths, filepath.Join(configHome, "git", "config"))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".gitconfig"))
+	}
+	return paths
+}
+
+func xdgConfigHome() string {
+	if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
+		return configHome
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".config")
+	}
+	return ""
+}
+
+// gitConfig holds the values of a git config file by lowercased
+// `section.key`. Subsections are ignored, since none of the values
+// that are used live in one.
+type gitConfig map[string]string
+
+func (c gitConfig) read(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	section := ""
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				return fmt.Errorf("%s: bad section %q", path, line)
+			}
+			name, subsection, _ := strings.Cut(line[1:end], " ")
+			section = strings.ToLower(name)
+			if subsection != "" {
+				section = ""
+			}
+			continue
+		}
+		if section == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(line, "=")
+		c[section+"."+strings.ToLower(strings.TrimSpace(key))] = parseConfigValue(value)
+	}
+	return scanner.Err()
+}
+
+// parseConfigValue removes the quotes and trailing comment of a value.
+func parseConfigValue(value string) string {
+	var b strings.Builder
+	quoted := false
+	for i := 0; i < len(value); i++ {
+		switch ch := value[i]; {
+		case ch == '"':
+			quoted = !quoted
+		case ch == '\\' && i+1 < len(value):
+			i++
+			b.WriteByte(value[i])
+		case (ch == '#' || ch == ';') && !quoted:
+			return strings.TrimSpace(b.String())
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// expandHome expands a leading `~/` in path to the home directory.
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
+
+// UntrackedFiles returns the slash separated paths, relative to the work
+// tree, of the files that aren't in tracked and aren't ignored. Ignored
+// directories and nested repositories aren't searched.
+func (r *Repo) UntrackedFiles(tracked []string) ([]string, error) {
+	ignorer, err := r.Ignorer()
+	if err != nil {
+		return nil, err
+	}
+	trackedSet := map[string]struct{}{}
+	for _, path := range tracked {
+		trackedSet[path] = struct{}{}
+	}
+	untracked := []string{}
+	err = filepath.WalkDir(r.WorkTree, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == r.WorkTree {
+			return nil
+		}
+		if d.Name() == ".git" {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relPath, err := filepath.Rel(r.WorkTree, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if d.IsDir() {
+			if _, err := os.Lstat(filepath.Join(path, ".git")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+		ignored, err := ignorer.IsIgnored(relPath, d.IsDir())
+		if err != nil {
+			return err
+		}
+		if ignored {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if _, ok := trackedSet[relPath]; !ok {
+			untracked = append(untracked, relPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return untracked, nil
+}
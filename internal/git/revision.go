@@ -0,0 +1,296 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var ErrBadRevision = errors.New("bad revision")
+
+// The ref namespaces that a short ref name is looked up in, in the order
+// of gitrevisions(7).
+var refLookupFormats = []string{
+	"%s",
+	"refs/%s",
+	"refs/tags/%s",
+	"refs/heads/%s",
+	"refs/remotes/%s",
+	"refs/remotes/%s/HEAD",
+}
+
+// ResolveCommit returns the hex hash of the commit that rev names. The
+// revision is a full or abbreviated hash or a ref name, optionally
+// followed by any number of `~<n>` and `^<n>` ancestor suffixes.
+func (r *Repo) ResolveCommit(rev string) (string, error) {
+	name, suffixes := rev, ""
+	if i := strings.IndexAny(rev, "~^"); i >= 0 {
+		name, suffixes = rev[:i], rev[i:]
+	}
+	if name == "" || name == "@" {
+		name = "HEAD"
+	}
+	id, err := r.resolveName(name)
+	if err != nil {
+		return "", fmt.Errorf("%w %q: %w", ErrBadRevision, rev, err)
+	}
+	id, err = r.peelToCommit(id)
+	if err != nil {
+		return "", fmt.Errorf("%w %q: %w", ErrBadRevision, rev, err)
+	}
+
+	for suffixes != "" {
+		op := suffixes[0]
+		suffixes = suffixes[1:]
+		digits := len(suffixes) - len(strings.TrimLeft(suffixes, "0123456789"))
+		n := 1
+		if digits > 0 {
+			n, err = strconv.Atoi(suffixes[:digits])
+			if err != nil {
+				return "", fmt.Errorf("%w %q: %w", ErrBadRevision, rev, err)
+			}
+			suffixes = suffixes[digits:]
+		}
+		if op == '^' {
+			// ^n is the nth parent, and ^0 is the commit itself.
+			if n == 0 {
+				continue
+			}
+			id, err = r.parent(id, n)
+		} else {
+			// ~n is the nth generation of first parents.
+			for i := 0; i < n && err == nil; i++ {
+				id, err = r.parent(id, 1)
+			}
+		}
+		if err != nil {
+			return "", fmt.Errorf("%w %q: %w", ErrBadRevision, rev, err)
+		}
+	}
+	return id, nil
+}
+
+func (r *Repo) resolveName(name string) (string, error) {
+	store, err := r.objects()
+	if err != nil {
+		return "", err
+	}
+	for _, format := range refLookupFormats {
+		id, err := r.readRef(fmt.Sprintf(format, name), 0)
+		if err != nil {
+			return "", err
+		}
+		if id != "" {
+			return id, nil
+		}
+	}
+	if len(name) >= 4 && len(name) <= store.hashSize*2 && isHex(name) {
+		ids, err := store.expand(strings.ToLower(name))
+		if err != nil {
+			return "", err
+		}
+		switch len(ids) {
+		case 0:
+		case 1:
+			return ids[0], nil
+		default:
+			return "", fmt.Errorf("%s is ambiguous", name)
+		}
+	}
+	return "", errors.New("unknown revision or ref")
+}
+
+func isHex(s string) bool {
+	return strings.Trim(strings.ToLower(s), "0123456789abcdef") == ""
+}
+
+// readRef returns the hash that the ref points to, following symbolic
+// refs, or an empty string if there is no such ref.
+func (r *Repo) readRef(ref string, depth int) (string, error) {
+	if depth > 5 {
+		return "", fmt.Errorf("%s: too many levels of symbolic refs", ref)
+	}
+	// Refs outside of refs/, like HEAD, belong to the worktree.
+	dir := r.CommonDir
+	if !strings.HasPrefix(ref, "refs/") {
+		dir = r.GitDir
+	}
+	refPath := filepath.Join(dir, filepath.FromSlash(path.Clean(ref)))
+	content, err := os.ReadFile(refPath)
+	if err == nil {
+		value := strings.TrimSpace(string(content))
+		if target, ok := strings.CutPrefix(value, "ref:"); ok {
+			return r.readRef(strings.TrimSpace(target), depth+1)
+		}
+		return value, nil
+	}
+	// A directory of refs with the same name isn't a ref.
+	if info, statErr := os.Stat(refPath); !os.IsNotExist(err) && (statErr != nil || !info.IsDir()) {
+		return "", err
+	}
+	return r.readPackedRef(ref)
+}
+
+func (r *Repo) readPackedRef(ref string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(r.CommonDir, "packed-refs"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		// Lines starting with ^ are the peeled value of the ref before
+		// them, which peelToCommit finds anyway.
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+		id, name, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if ok && name == ref {
+			return id, nil
+		}
+	}
+	return "", nil
+}
+
+// peelToCommit follows annotated tags to the commit they point to.
+func (r *Repo) peelToCommit(id string) (string, error) {
+	for i := 0; ; i++ {
+		objectType, content, err := r.ReadObject(id)
+		if err != nil {
+			return "", err
+		}
+		switch objectType {
+		case ObjectCommit:
+			return id, nil
+		case ObjectTag:
+			target := objectHeader(content, "object")
+			if target == "" || i > 10 {
+				return "", fmt.Errorf("%w: tag %s has no object", ErrBadObject, id)
+			}
+			id = target
+		default:
+			return "", fmt.Errorf("%s is a %s, not a commit", id, objectType)
+		}
+	}
+}
+
+func (r *Repo) parent(commit string, n int) (string, error) {
+	_, content, err := r.ReadObject(commit)
+	if err != nil {
+		return "", err
+	}
+	parents := objectHeaders(content, "parent")
+	if n > len(parents) {
+		return "", fmt.Errorf("commit %s has no parent %d", commit, n)
+	}
+	return parents[n-1], nil
+}
+
+// objectHeaders returns the values of the header lines named key at the
+// start of a commit or tag.
+func objectHeaders(content []byte, key string) []string {
+	values := []string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		if line == "" {
+			break
+		}
+		if value, ok := strings.CutPrefix(line, key+" "); ok {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func objectHeader(content []byte, key string) string {
+	values := objectHeaders(content, key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// TreeEntry is a file in a tree.
+type TreeEntry struct {
+	Mode uint32
+	ID   string
+}
+
+// CommitFiles returns the files in the tree of the commit, by slash
+// separated path relative to the work tree. Submodules are left out.
+func (r *Repo) CommitFiles(commit string) (map[string]TreeEntry, error) {
+	objectType, content, err := r.ReadObject(commit)
+	if err != nil {
+		return nil, err
+	}
+	if objectType != ObjectCommit {
+		return nil, fmt.Errorf("%s is a %s, not a commit", commit, objectType)
+	}
+	tree := objectHeader(content, "tree")
+	if tree == "" {
+		return nil, fmt.Errorf("%w: commit %s has no tree", ErrBadObject, commit)
+	}
+	files := map[string]TreeEntry{}
+	if err := r.readTree(tree, "", files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// readTree adds the files of the tree object to files, reading the trees
+// of subdirectories as it goes. Each entry is `<octal mode> <name>\0`
+// followed by the raw hash.
+func (r *Repo) readTree(id string, prefix string, files map[string]TreeEntry) error {
+	objectType, content, err := r.ReadObject(id)
+	if err != nil {
+		return err
+	}
+	if objectType != ObjectTree {
+		return fmt.Errorf("%s is a %s, not a tree", id, objectType)
+	}
+	hashSize := r.hashSize()
+	for len(content) > 0 {
+		header, rest, ok := bytes.Cut(content, []byte{0})
+		if !ok || len(rest) < hashSize {
+			return fmt.Errorf("%w: tree %s is truncated", ErrBadObject, id)
+		}
+		modeStr, name, ok := strings.Cut(string(header), " ")
+		mode, err := strconv.ParseUint(modeStr, 8, 32)
+		if !ok || err != nil {
+			return fmt.Errorf("%w: tree %s has a bad entry", ErrBadObject, id)
+		}
+		entry := TreeEntry{Mode: uint32(mode), ID: hex.EncodeToString(rest[:hashSize])}
+		content = rest[hashSize:]
+
+		switch entry.Mode & modeTypeMask {
+		case modeTypeTree:
+			if err := r.readTree(entry.ID, prefix+name+"/", files); err != nil {
+				return err
+			}
+		case modeTypeLink:
+		default:
+			files[prefix+name] = entry
+		}
+	}
+	return nil
+}
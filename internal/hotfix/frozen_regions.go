@@ -0,0 +1,298 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hotfix
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/yamlfmt"
+	"github.com/google/yamlfmt/pkg/yaml"
+)
+
+// Regions marked by `!yamlfmt!:off` and `!yamlfmt!:on` comments, and the
+// nodes after `!yamlfmt!:ignore-next` comments, are replaced with a
+// placeholder comment before formatting, and put back as they were
+// written once the rest of the document is formatted.
+const frozenPlaceholderPrefix = "#__yamlfmt_frozen_"
+
+var frozenPlaceholderRegex = regexp.MustCompile(`^` + frozenPlaceholderPrefix + `(\d+)__$`)
+
+type frozenRegionsKey string
+
+var contextFrozenRegionsKey frozenRegionsKey = "frozen_regions"
+
+type frozenRegion struct {
+	// The line the region starts on, starting from 1.
+	line  int
+	lines []string
+	// The indentation of the placeholder, which is that of the first node
+	// in the region.
+	indent int
+	// Whether a blank line came before the region.
+	blankBefore bool
+}
+
+func contextWithFrozenRegions(ctx context.Context, regions []frozenRegion) context.Context {
+	return context.WithValue(ctx, contextFrozenRegionsKey, regions)
+}
+
+func frozenRegionsFromContext(ctx context.Context) []frozenRegion {
+	regions, _ := ctx.Value(contextFrozenRegionsKey).([]frozenRegion)
+	return regions
+}
+
+// MakeFeaturesFrozenRegions returns a feature that takes the frozen
+// regions out, and a feature that puts them back. Features run their
+// BeforeAction and AfterAction in the same order, so the first goes at
+// the start of the feature list, before anything else changes the
+// content, and the second at the end, after everything else is done.
+func MakeFeaturesFrozenRegions(lineSepChar string) (yamlfmt.Feature, yamlfmt.Feature) {
+	freeze := yamlfmt.Feature{
+		Name:         "Freeze Regions",
+		BeforeAction: freezeRegionsFeature,
+	}
+	restore := yamlfmt.Feature{
+		Name:        "Restore Frozen Regions",
+		AfterAction: restoreFrozenRegionsFeature(lineSepChar),
+	}
+	return freeze, restore
+}
+
+func freezeRegionsFeature(ctx context.Context, content []byte) (context.Context, []byte, error) {
+	regions := []frozenRegion{}
+	if !bytes.Contains(content, []byte(yamlfmt.MetadataIdentifier)) {
+		return contextWithFrozenRegions(ctx, regions), content, nil
+	}
+	if bytes.Contains(content, []byte(frozenPlaceholderPrefix)) {
+		return ctx, nil, fmt.Errorf("content already contains the placeholder %s", frozenPlaceholderPrefix)
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	markers := regionMarkers(content, lines)
+
+	var result strings.Builder
+	for i := 0; i < len(lines); i++ {
+		var end int
+		switch markers[i] {
+		case yamlfmt.MetadataOff:
+			end = offRegionEnd(lines, markers, i)
+		case yamlfmt.MetadataIgnoreNext:
+			start := nextNodeLine(lines, i+1)
+			if start < 0 {
+				return ctx, nil, fmt.Errorf("%s:%s on line %d has no node after it", yamlfmt.MetadataIdentifier, yamlfmt.MetadataIgnoreNext, i+1)
+			}
+			end = nodeEnd(lines, start)
+		case yamlfmt.MetadataOn:
+			return ctx, nil, fmt.Errorf("%s:%s on line %d has no %s:%s before it", yamlfmt.MetadataIdentifier, yamlfmt.MetadataOn, i+1, yamlfmt.MetadataIdentifier, yamlfmt.MetadataOff)
+		default:
+			result.WriteString(lines[i])
+			continue
+		}
+
+		region := frozenRegion{
+			line:        i + 1,
+			lines:       lines[i : end+1],
+			indent:      indentOf(lines[i]),
+			blankBefore: i > 0 && isBlankLine(lines[i-1]),
+		}
+		if start := nextNodeLine(lines[:end+1], i+1); start >= 0 {
+			region.indent = indentOf(lines[start])
+		}
+		fmt.Fprintf(&result, "%s%s%d__\n", strings.Repeat(" ", region.indent), frozenPlaceholderPrefix, len(regions))
+		regions = append(regions, region)
+		i = end
+	}
+	frozen := []byte(result.String())
+
+	if len(regions) > 0 && !parses(frozen) && parses(content) {
+		return ctx, nil, fmt.Errorf(
+			"the yaml is invalid without the regions that %s comments leave as written, make sure each region covers whole nodes",
+			yamlfmt.MetadataIdentifier,
+		)
+	}
+	return contextWithFrozenRegions(ctx, regions), frozen, nil
+}
+
+// regionMarkers finds the comment lines with metadata that marks a frozen
+// region, by line index.
+func regionMarkers(content []byte, lines []string) map[int]yamlfmt.MetadataType {
+	markers := map[int]yamlfmt.MetadataType{}
+	// Malformed metadata is reported when paths are analyzed.
+	metadata, _ := yamlfmt.ReadMetadata(content, "")
+	for md := range metadata {
+		switch md.Type {
+		case yamlfmt.MetadataOff, yamlfmt.MetadataOn, yamlfmt.MetadataIgnoreNext:
+			if isCommentLine(lines[md.LineNum-1]) {
+				markers[md.LineNum-1] = md.Type
+			}
+		}
+	}
+	return markers
+}
+
+// offRegionEnd returns the index of the last line of the region that
+// starts at the off comment on line index start. The region runs to the
+// next on comment, or else to the end of the document.
+func offRegionEnd(lines []string, markers map[int]yamlfmt.MetadataType, start int) int {
+	for i := start + 1; i < len(lines); i++ {
+		if markers[i] == yamlfmt.MetadataOn {
+			return i
+		}
+		if isDocumentSeparator(lines[i]) {
+			return i - 1
+		}
+	}
+	return len(lines) - 1
+}
+
+// nextNodeLine returns the index of the first line from start that isn't
+// blank or a comment, or -1 if the document ends first.
+func nextNodeLine(lines []string, start int) int {
+	for i := start; i < len(lines); i++ {
+		if isDocumentSeparator(lines[i]) {
+			return -1
+		}
+		if !isBlankLine(lines[i]) && !isCommentLine(lines[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// nodeEnd returns the index of the last line of the node that starts on
+// line index start: the lines after it that are indented further, the
+// items of an indentless sequence that is its value, and the closing
+// bracket of a flow collection. Blank lines and comments at the end are
+// left out.
+func nodeEnd(lines []string, start int) int {
+	indent := indentOf(lines[start])
+	isItem := isSequenceItem(lines[start])
+	end := start
+	for i := start + 1; i < len(lines); i++ {
+		line := lines[i]
+		if isDocumentSeparator(line) {
+			break
+		}
+		if isBlankLine(line) || isCommentLine(line) {
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		lineIndent := indentOf(line)
+		belongs := lineIndent > indent ||
+			(lineIndent == indent && !isItem && isSequenceItem(line)) ||
+			(lineIndent == indent && (trimmed[0] == ']' || trimmed[0] == '}'))
+		if !belongs {
+			break
+		}
+		end = i
+	}
+	return end
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func isBlankLine(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func isCommentLine(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "#")
+}
+
+func isSequenceItem(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "-" || strings.HasPrefix(trimmed, "- ")
+}
+
+func isDocumentSeparator(line string) bool {
+	line = strings.TrimRight(line, " \t\r\n")
+	return line == "---" || line == "..." || strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "... ")
+}
+
+func parses(content []byte) bool {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return true
+		}
+		if err != nil {
+			return false
+		}
+	}
+}
+
+func restoreFrozenRegionsFeature(lineSepChar string) yamlfmt.FeatureFunc {
+	return func(ctx context.Context, content []byte) (context.Context, []byte, error) {
+		regions := frozenRegionsFromContext(ctx)
+		if len(regions) == 0 {
+			return ctx, content, nil
+		}
+		var result strings.Builder
+		restored := 0
+		for _, line := range strings.SplitAfter(string(content), "\n") {
+			match := frozenPlaceholderRegex.FindStringSubmatch(strings.TrimSpace(line))
+			if match == nil {
+				result.WriteString(line)
+				continue
+			}
+			id, _ := strconv.Atoi(match[1])
+			if id != restored || id >= len(regions) {
+				break
+			}
+			region := regions[id]
+			// The formatter puts a blank line before a comment that ends a
+			// document, which the placeholder can be.
+			if !region.blankBefore {
+				trimmed := strings.TrimRight(result.String(), " \t\r\n")
+				if out := result.String(); strings.Count(out[len(trimmed):], "\n") > 1 {
+					result.Reset()
+					result.WriteString(trimmed + lineSepChar)
+				}
+			}
+			// The region moves with the indentation of the nodes around it,
+			// so that it still belongs to the same parent.
+			shift := indentOf(line) - region.indent
+			for _, regionLine := range region.lines {
+				regionLine = strings.TrimRight(regionLine, "\r\n")
+				if !isBlankLine(regionLine) {
+					if shift > 0 {
+						regionLine = strings.Repeat(" ", shift) + regionLine
+					} else {
+						regionLine = regionLine[min(-shift, indentOf(regionLine)):]
+					}
+				}
+				result.WriteString(regionLine + lineSepChar)
+			}
+			restored++
+		}
+		if restored != len(regions) {
+			return ctx, nil, fmt.Errorf(
+				"formatting moved the region on line %d that %s comments leave as written, so it can't be put back safely",
+				regions[restored].line, yamlfmt.MetadataIdentifier,
+			)
+		}
+		return ctx, []byte(result.String()), nil
+	}
+}
@@ -0,0 +1,345 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hotfix
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/yamlfmt"
+	"github.com/google/yamlfmt/pkg/yaml"
+)
+
+// Go template actions are masked with placeholders that the formatter
+// leaves alone: an action within a line becomes a plain scalar, and lines
+// made up of actions alone, such as control flow, become a comment.
+const templatePlaceholderPrefix = "__yamlfmt_tmpl_"
+
+var templatePlaceholderRegex = regexp.MustCompile(templatePlaceholderPrefix + `(\d+)__`)
+
+type templatesKey string
+
+var contextTemplatesKey templatesKey = "templates"
+
+// maskedTemplate is the original text of a masked action, or of the lines
+// of a masked control line, and the line it started on. indent is the
+// indentation of a control line, and yamlIndent that of the yaml line
+// before it.
+type maskedTemplate struct {
+	line       int
+	content    string
+	isLine     bool
+	indent     int
+	yamlIndent int
+}
+
+func contextWithTemplates(ctx context.Context, templates []maskedTemplate) context.Context {
+	return context.WithValue(ctx, contextTemplatesKey, templates)
+}
+
+func templatesFromContext(ctx context.Context) []maskedTemplate {
+	templates, _ := ctx.Value(contextTemplatesKey).([]maskedTemplate)
+	return templates
+}
+
+func MakeFeatureGoTemplates(lineSepChar string) yamlfmt.Feature {
+	return yamlfmt.Feature{
+		Name:         "Go Templates",
+		BeforeAction: maskTemplatesFeature,
+		AfterAction:  restoreTemplatesFeature(lineSepChar),
+	}
+}
+
+// templateAction is a `{{ ... }}` action, as byte offsets in the content.
+type templateAction struct {
+	start int
+	end   int
+}
+
+func maskTemplatesFeature(ctx context.Context, content []byte) (context.Context, []byte, error) {
+	if bytes.Contains(content, []byte(templatePlaceholderPrefix)) {
+		return ctx, nil, fmt.Errorf("content already contains the template placeholder %s", templatePlaceholderPrefix)
+	}
+	actions, err := findTemplateActions(content)
+	if err != nil {
+		return ctx, nil, err
+	}
+	templates := []maskedTemplate{}
+	if len(actions) == 0 {
+		return contextWithTemplates(ctx, templates), content, nil
+	}
+
+	var result bytes.Buffer
+	pos := 0
+	next := 0
+	for next < len(actions) {
+		lineStart := bytes.LastIndexByte(content[:actions[next].start], '\n') + 1
+		if lineEnd, ok := controlLineEnd(content, lineStart, actions[next:]); ok {
+			// The line holds nothing but actions, so it and the lines
+			// those actions run over are masked with a comment.
+			result.Write(content[pos:lineStart])
+			indent := len(content[lineStart:]) - len(bytes.TrimLeft(content[lineStart:], " \t"))
+			result.Write(content[lineStart : lineStart+indent])
+			fmt.Fprintf(&result, "#%s%d__", templatePlaceholderPrefix, len(templates))
+			templates = append(templates, maskedTemplate{
+				line:       lineNumber(content, lineStart),
+				content:    string(content[lineStart:lineEnd]),
+				isLine:     true,
+				indent:     indent,
+				yamlIndent: previousYamlIndent(content[:lineStart]),
+			})
+			for next < len(actions) && actions[next].start < lineEnd {
+				next++
+			}
+			pos = lineEnd
+			continue
+		}
+
+		action := actions[next]
+		if bytes.IndexByte(content[action.start:action.end], '\n') >= 0 {
+			return ctx, nil, fmt.Errorf(
+				"the go template action on line %d runs over several lines and shares a line with yaml, so it can't be masked; put it on lines of its own",
+				lineNumber(content, action.start),
+			)
+		}
+		result.Write(content[pos:action.start])
+		fmt.Fprintf(&result, "%s%d__", templatePlaceholderPrefix, len(templates))
+		templates = append(templates, maskedTemplate{
+			line:    lineNumber(content, action.start),
+			content: string(content[action.start:action.end]),
+		})
+		pos = action.end
+		next++
+	}
+	result.Write(content[pos:])
+	masked := result.Bytes()
+
+	// If the yaml doesn't parse with the actions masked, they likely
+	// choose between alternatives, such as the same key in both branches of
+	// an if, and there is no single structure to format.
+	decoder := yaml.NewDecoder(bytes.NewReader(masked))
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return ctx, nil, fmt.Errorf("the go template actions make the yaml structure ambiguous, so it can't be formatted (the yaml with the actions masked is invalid: %w)", err)
+		}
+	}
+	return contextWithTemplates(ctx, templates), masked, nil
+}
+
+// controlLineEnd returns the end of the line that starts at lineStart, if
+// the line holds nothing but the first of actions and any that follow it.
+// The end includes the lines that the last action runs over, but not the
+// final line break.
+func controlLineEnd(content []byte, lineStart int, actions []templateAction) (int, bool) {
+	pos := lineStart
+	for _, action := range actions {
+		if len(bytes.Trim(content[pos:action.start], " \t")) > 0 {
+			break
+		}
+		pos = action.end
+		if i := bytes.IndexByte(content[pos:], '\n'); i >= 0 && len(bytes.TrimRight(content[pos:pos+i], " \t\r")) == 0 {
+			return pos + len(bytes.TrimRight(content[pos:pos+i], "\r")), true
+		} else if i < 0 && len(bytes.TrimSpace(content[pos:])) == 0 {
+			return len(content), true
+		}
+	}
+	return 0, false
+}
+
+// findTemplateActions finds the `{{ ... }}` actions in content. Quoted
+// strings and comments within an action can contain `}}`.
+func findTemplateActions(content []byte) ([]templateAction, error) {
+	actions := []templateAction{}
+	pos := 0
+	for {
+		i := bytes.Index(content[pos:], []byte("{{"))
+		if i < 0 {
+			return actions, nil
+		}
+		start := pos + i
+		end, err := templateActionEnd(content, start)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, templateAction{start: start, end: end})
+		pos = end
+	}
+}
+
+func templateActionEnd(content []byte, start int) (int, error) {
+	unclosed := fmt.Errorf("the go template action on line %d is never closed", lineNumber(content, start))
+	pos := start + 2
+	body := bytes.TrimLeft(bytes.TrimPrefix(content[pos:], []byte("-")), " \t\r\n")
+	if bytes.HasPrefix(body, []byte("/*")) {
+		i := bytes.Index(content[pos:], []byte("*/"))
+		if i < 0 {
+			return 0, unclosed
+		}
+		pos += i + 2
+	}
+	for pos < len(content) {
+		switch c := content[pos]; c {
+		case '"', '\'', '`':
+			end := quotedEnd(content, pos)
+			if end < 0 {
+				return 0, unclosed
+			}
+			pos = end
+		case '}':
+			if bytes.HasPrefix(content[pos:], []byte("}}")) {
+				return pos + 2, nil
+			}
+			pos++
+		default:
+			pos++
+		}
+	}
+	return 0, unclosed
+}
+
+// quotedEnd returns the offset after the string or character literal that
+// starts at start, or -1 if it isn't closed.
+func quotedEnd(content []byte, start int) int {
+	quote := content[start]
+	for pos := start + 1; pos < len(content); pos++ {
+		switch content[pos] {
+		case quote:
+			return pos + 1
+		case '\\':
+			if quote != '`' {
+				pos++
+			}
+		case '\n':
+			if quote != '`' {
+				return -1
+			}
+		}
+	}
+	return -1
+}
+
+// previousYamlIndent returns the indentation of the last line of content
+// that is yaml, rather than blank, a comment or a template action.
+func previousYamlIndent(content []byte) int {
+	lines := strings.Split(string(content), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if isYamlLine(lines[i]) {
+			return indentOf(lines[i])
+		}
+	}
+	return 0
+}
+
+func isYamlLine(line string) bool {
+	return !isBlankLine(line) && !isCommentLine(line) && !strings.HasPrefix(strings.TrimSpace(line), "{{")
+}
+
+func lineNumber(content []byte, offset int) int {
+	return bytes.Count(content[:offset], []byte("\n")) + 1
+}
+
+func restoreTemplatesFeature(lineSepChar string) yamlfmt.FeatureFunc {
+	return func(ctx context.Context, content []byte) (context.Context, []byte, error) {
+		templates := templatesFromContext(ctx)
+		if len(templates) == 0 {
+			return ctx, content, nil
+		}
+		restored := 0
+		var restoreErr error
+		restore := func(match string) string {
+			id, _ := strconv.Atoi(templatePlaceholderRegex.FindStringSubmatch(match)[1])
+			// The actions must come back in the order they were masked in,
+			// or formatting has moved them somewhere they may not belong.
+			if restoreErr == nil && (id != restored || id >= len(templates)) {
+				restoreErr = fmt.Errorf("formatting moved the go template action on line %d, so the template can't be formatted safely", templates[min(restored, len(templates)-1)].line)
+			}
+			restored++
+			return templates[min(id, len(templates)-1)].content
+		}
+
+		lines := strings.SplitAfter(string(content), "\n")
+		var result strings.Builder
+		yamlIndent := 0
+		for _, line := range lines {
+			if isYamlLine(line) {
+				yamlIndent = indentOf(line)
+			}
+			trimmed := strings.TrimSpace(line)
+			if placeholder, ok := strings.CutPrefix(trimmed, "#"); ok && templatePlaceholderRegex.MatchString(placeholder) &&
+				templatePlaceholderRegex.FindString(placeholder) == placeholder {
+				id, _ := strconv.Atoi(templatePlaceholderRegex.FindStringSubmatch(placeholder)[1])
+				original := restore(placeholder)
+				original = strings.ReplaceAll(original, "\r\n", "\n")
+				// The control line moves with the indentation of the yaml
+				// around it, so that the rendered template lines up. A
+				// control line that was nested under the yaml line before
+				// it, with no yaml of its own after it, is placed at the
+				// level of that line by the formatter, so it stays nested.
+				template := templates[min(id, len(templates)-1)]
+				indent := indentOf(line)
+				if nested := template.indent - template.yamlIndent; nested > 0 && indent <= yamlIndent {
+					// The nesting scales with the indentation of the line
+					// before it, when that tells how it changed.
+					if template.yamlIndent > 0 && yamlIndent > 0 {
+						nested = nested * yamlIndent / template.yamlIndent
+					}
+					indent = yamlIndent + nested
+				}
+				shift := indent - template.indent
+				originalLines := strings.Split(original, "\n")
+				for i, originalLine := range originalLines {
+					if isBlankLine(originalLine) {
+						continue
+					}
+					if shift > 0 {
+						originalLines[i] = strings.Repeat(" ", shift) + originalLine
+					} else {
+						originalLines[i] = originalLine[min(-shift, indentOf(originalLine)):]
+					}
+				}
+				result.WriteString(strings.Join(originalLines, lineSepChar))
+				if strings.HasSuffix(line, "\n") {
+					result.WriteString(lineSepChar)
+				}
+				continue
+			}
+			result.WriteString(templatePlaceholderRegex.ReplaceAllStringFunc(line, func(match string) string {
+				id, _ := strconv.Atoi(templatePlaceholderRegex.FindStringSubmatch(match)[1])
+				if restoreErr == nil && id < len(templates) && templates[id].isLine {
+					restoreErr = fmt.Errorf("formatting moved the go template action on line %d onto a line with yaml, so the template can't be formatted safely", templates[id].line)
+				}
+				return restore(match)
+			}))
+		}
+		if restoreErr == nil && restored != len(templates) {
+			restoreErr = fmt.Errorf("formatting dropped the go template action on line %d, so the template can't be formatted safely", templates[min(restored, len(templates)-1)].line)
+		}
+		if restoreErr != nil {
+			return ctx, nil, restoreErr
+		}
+		return ctx, []byte(result.String()), nil
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+// descriptions are keyed by the dotted path of each config key. Keys of
+// the formatter are under `formatter.`, and keys of the objects in a
+// list are under the name of the list.
+var descriptions = map[string]string{
+	"extensions":              "The extensions to use for standard mode path collection. See Specifying Paths for more details.",
+	"filenames":               "File name patterns, such as .clang-format or Chart.lock, for files to collect in standard and git mode whatever their extension. See Specifying Paths for more details.",
+	"sniff_content":           "In standard and git mode, also collect files without an extension whose content starts like YAML. See Specifying Paths for more details.",
+	"front_matter_extensions": "Extensions of files, such as md, that only have their front matter formatted. The front matter is the YAML between --- lines at the start of the file, and the rest of the file is left untouched. These files are collected in addition to those with one of the extensions.",
+	"code_block_extensions":   "Extensions of Markdown files, such as md, that only have their fenced code blocks tagged yaml or yml formatted. The rest of the file is left untouched.",
+	"match_type":              "Controls how include and exclude are interpreted. See Specifying Paths for more details.",
+	"include":                 "The paths for the command to include for formatting. See Specifying Paths for more details.",
+	"exclude":                 "The paths for the command to exclude from formatting. See Specifying Paths for more details.",
+	"regex_exclude":           "Regex patterns to match file contents for, if the file content matches the regex the file will be excluded. Use Go regexes.",
+	"exclude_generated":       "Exclude files with a '# Code generated ... DO NOT EDIT.' comment line.",
+	"max_file_size":           "Exclude files larger than this many bytes, without reading them. 0 means no limit.",
+	"exclude_binary":          "Exclude files that have a NUL byte or aren't valid UTF-8.",
+	"exclude_invalid_yaml":    "Exclude files that aren't valid YAML instead of failing to format them.",
+	"formatter":               "Formatter settings. See Formatter for more details.",
+	"doublestar":              "Use doublestar for include and exclude paths. (This was the default before 0.7.0)",
+	"continue_on_error":       "Continue formatting and don't exit with code 1 when there is an invalid yaml file found.",
+	"line_ending":             "Parse and write the file with 'lf' or 'crlf' line endings, or detect them per file with 'auto'. This global setting will override any formatter line_ending options.",
+	"bom":                     "What to do with a UTF-8 byte order mark: keep it where it exists, strip it, or add it to every file.",
+	"gitignore_excludes":      "Use gitignore files for exclude paths. This is in addition to the patterns from the exclude option.",
+	"gitignore_path":          "The name of the gitignore files to use in every directory, or the path to a single gitignore file.",
+	"output_format":           "The output format to use. See Output docs for more details.",
+	"overrides":               "Formatter settings for specific paths. See Overrides for more details.",
+	"overrides.include":       "Doublestar patterns for the paths the override applies to, relative to the directory of the config file.",
+	"overrides.exclude":       "Doublestar patterns for paths to leave out even if they match include.",
+	"overrides.formatter":     "Formatter settings merged over the top level formatter settings for the matching paths.",
+	"nested_configs":          "Use the nearest config file for each formatted file. See Nested Config Files for more details.",
+	"inherit":                 "In a nested config file, merge the formatter settings over those of the next config file up the tree.",
+	"editorconfig":            "Read basic formatter settings for each file from .editorconfig files. Settings configured for yamlfmt take precedence.",
+	"disable_strict_config":   "Ignore unknown keys in the config file instead of failing.",
+	"git_untracked":           "With match_type git, also collect untracked files that git doesn't ignore.",
+	"changed_since":           "Only format files that were added or modified in the git working tree since this revision, such as a branch, tag or commit hash.",
+	"changed_lines":           "With changed_since, only format the top level nodes that contain lines added or changed since the revision, and leave the rest of each file untouched.",
+	"symlinks":                "How to treat symlinks when collecting paths: skip them, follow them, or only follow the ones that lead within the working directory. Each file is only formatted once, however many paths lead to it.",
+	"extends":                 "Config files to merge beneath this one. Relative paths are resolved from the directory of this config file.",
+
+	"formatter.type":                         "The formatter to use.",
+	"formatter.indent":                       "The indentation level in spaces to use for the formatted yaml.",
+	"formatter.include_document_start":       "Include --- at document start.",
+	"formatter.line_ending":                  "Parse and write the file with 'lf' or 'crlf' line endings, or detect them from the input with 'auto'. This setting will be overwritten by the global line_ending.",
+	"formatter.max_line_length":              "Set the maximum line length. If not set, defaults to 0 which means no limit.",
+	"formatter.retain_line_breaks":           "Retain line breaks in formatted yaml.",
+	"formatter.retain_line_breaks_single":    "Retain line breaks in formatted yaml, but only keep a single line in groups of many blank lines. Takes precedence over retain_line_breaks.",
+	"formatter.disallow_anchors":             "If true, reject any yaml anchors or aliases found in the document.",
+	"formatter.scan_folded_as_literal":       "Preserve newlines in folded block scalars (blocks that start with >).",
+	"formatter.indentless_arrays":            "Render - array items (block sequence items) without an increased indent.",
+	"formatter.drop_merge_tag":               "Assume that any well formed merge using just a << token will be a merge, and drop the !!merge tag from the formatted result.",
+	"formatter.pad_line_comments":            "The number of padding spaces to insert before line comments.",
+	"formatter.trim_trailing_whitespace":     "Trim trailing whitespace from lines.",
+	"formatter.eof_newline":                  "Always add a newline at end of file.",
+	"formatter.go_templates":                 "Mask Go template actions, such as those in Helm chart templates, before formatting and put them back afterwards.",
+	"formatter.strip_directives":             "Attempt to strip yaml directives before formatting and put them back afterwards. Use at your own risk.",
+	"formatter.array_indent":                 "Set a different indentation level for block sequences specifically. Defaults to indent.",
+	"formatter.indent_root_array":            "Indent an array that is at the lowest indentation level of the document.",
+	"formatter.disable_alias_key_correction": "Disable the fix for alias nodes being used as keys.",
+	"formatter.force_array_style":            "If set, forces arrays to be output in either flow ([]) or block (- x) style.",
+	"formatter.force_quote_style":            "If set, forces all nodes with quotes into either single or double quotes.",
+}
+
+// Description returns the description of the config key at the dotted
+// path key, or an empty string if there isn't one.
+func Description(key string) string {
+	return descriptions[key]
+}
@@ -0,0 +1,237 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/yamlfmt"
+	"github.com/google/yamlfmt/command"
+	"github.com/google/yamlfmt/engine"
+	"github.com/google/yamlfmt/formatters/basic"
+	yamlFeatures "github.com/google/yamlfmt/formatters/basic/features"
+	"github.com/google/yamlfmt/formatters/kyaml"
+)
+
+const formatterRef = "#/definitions/formatter"
+
+// requiredKeys are the keys that the objects in a list must have.
+var requiredKeys = map[string][]string{
+	"overrides": {"include"},
+}
+
+// enums are the valid values of the string types used in the config. An
+// empty string means the setting can be left unset.
+var enums = map[reflect.Type][]string{
+	reflect.TypeFor[yamlfmt.LineBreakStyle](): {
+		string(yamlfmt.LineBreakStyleLF),
+		string(yamlfmt.LineBreakStyleCRLF),
+		string(yamlfmt.LineBreakStyleAuto),
+	},
+	reflect.TypeFor[yamlfmt.BOMMode](): {
+		string(yamlfmt.BOMModePreserve),
+		string(yamlfmt.BOMModeStrip),
+		string(yamlfmt.BOMModeAdd),
+	},
+	reflect.TypeFor[yamlfmt.MatchType](): {
+		string(yamlfmt.MatchTypeStandard),
+		string(yamlfmt.MatchTypeDoublestar),
+		string(yamlfmt.MatchTypeGitignore),
+		string(yamlfmt.MatchTypeGit),
+	},
+	reflect.TypeFor[yamlfmt.SymlinkPolicy](): {
+		string(yamlfmt.SymlinkPolicySkip),
+		string(yamlfmt.SymlinkPolicyFollow),
+		string(yamlfmt.SymlinkPolicyFollowWithinRoot),
+	},
+	reflect.TypeFor[engine.EngineOutputFormat](): {
+		string(engine.EngineOutputDefault),
+		string(engine.EngineOutputSingeLine),
+		string(engine.EngineOutputGitlab),
+		string(engine.EngineOutputJSON),
+	},
+	reflect.TypeFor[yamlFeatures.SequenceStyle](): {
+		"",
+		string(yamlFeatures.SequenceStyleBlock),
+		string(yamlFeatures.SequenceStyleFlow),
+	},
+	reflect.TypeFor[yamlFeatures.QuoteStyle](): {
+		"",
+		string(yamlFeatures.SingleQuoteStyle),
+		string(yamlFeatures.DoubleQuoteStyle),
+	},
+}
+
+// Generate returns the JSON Schema for the yamlfmt config file.
+func Generate() ([]byte, error) {
+	schema, err := configSchema()
+	if err != nil {
+		return nil, err
+	}
+	out, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+func configSchema() (*Schema, error) {
+	defaults := command.DefaultConfig()
+	// The default depends on the OS the schema is generated on, so
+	// always document the default that every other OS uses.
+	defaults.LineEnding = yamlfmt.LineBreakStyleLF
+
+	properties, err := structProperties("", reflect.ValueOf(*defaults), true)
+	if err != nil {
+		return nil, err
+	}
+	properties = append(properties, Property{
+		Name: "extends",
+		Schema: &Schema{
+			Description: descriptions["extends"],
+			OneOf: []*Schema{
+				{Type: "string"},
+				{Type: "array", Items: &Schema{Type: "string"}},
+			},
+		},
+	})
+
+	formatterDefaults := basic.DefaultConfig()
+	formatterDefaults.LineEnding = yamlfmt.LineBreakStyleLF
+	formatterProperties, err := structProperties("formatter.", reflect.ValueOf(*formatterDefaults), true)
+	if err != nil {
+		return nil, err
+	}
+	formatterProperties = append(Properties{{
+		Name: "type",
+		Schema: &Schema{
+			Type:        "string",
+			Enum:        []string{basic.BasicFormatterType, kyaml.KYAMLFormatterType},
+			Default:     basic.BasicFormatterType,
+			Description: descriptions["formatter.type"],
+		},
+	}}, formatterProperties...)
+
+	return &Schema{
+		SchemaURI:   "http://json-schema.org/draft-07/schema#",
+		ID:          "https://raw.githubusercontent.com/google/yamlfmt/main/schema.json",
+		Title:       "yamlfmt config file",
+		Description: "The yamlfmt config file. For details, see https://github.com/google/yamlfmt/blob/main/docs/config-file.md.",
+		Type:        "object",
+		Properties:  properties,
+		Definitions: Properties{{
+			Name: "formatter",
+			Schema: &Schema{
+				Type:        "object",
+				Description: descriptions["formatter"],
+				Properties:  formatterProperties,
+			},
+		}},
+		AdditionalProperties: ptr(false),
+	}, nil
+}
+
+// structProperties builds the properties of the mapstructure tagged fields
+// of v, using the values of the fields as defaults if withDefaults is set.
+// Every property must have a description.
+func structProperties(prefix string, v reflect.Value, withDefaults bool) (Properties, error) {
+	properties := Properties{}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "" {
+			continue
+		}
+		key := prefix + name
+		description, ok := descriptions[key]
+		if !ok {
+			return nil, fmt.Errorf("no description for config key %q", key)
+		}
+		schema, err := fieldSchema(key, v.Field(i), withDefaults)
+		if err != nil {
+			return nil, err
+		}
+		schema.Description = description
+		properties = append(properties, Property{Name: name, Schema: schema})
+	}
+	return properties, nil
+}
+
+func fieldSchema(key string, v reflect.Value, withDefaults bool) (*Schema, error) {
+	t := v.Type()
+	schema := &Schema{}
+	switch {
+	case t == reflect.TypeFor[*command.FormatterConfig]():
+		schema.Ref = formatterRef
+		return schema, nil
+	case t.Kind() == reflect.Bool:
+		schema.Type = "boolean"
+	case t.Kind() == reflect.Int:
+		schema.Type = "integer"
+	case t.Kind() == reflect.String:
+		schema.Type = "string"
+		schema.Enum = enums[t]
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String:
+		schema.Type = "array"
+		schema.Items = &Schema{Type: "string"}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Pointer && t.Elem().Elem().Kind() == reflect.Struct:
+		itemProperties, err := structProperties(key+".", reflect.New(t.Elem().Elem()).Elem(), false)
+		if err != nil {
+			return nil, err
+		}
+		schema.Type = "array"
+		schema.Items = &Schema{
+			Type:                 "object",
+			Properties:           itemProperties,
+			AdditionalProperties: ptr(false),
+		}
+		schema.Items.Required = requiredKeys[key]
+	default:
+		return nil, fmt.Errorf("config key %q has unsupported type %s", key, t)
+	}
+	if withDefaults {
+		schema.Default = defaultValue(v)
+	}
+	return schema, nil
+}
+
+func defaultValue(v reflect.Value) any {
+	if v.Kind() == reflect.Slice {
+		if v.Len() == 0 {
+			return []any{}
+		}
+		values := []any{}
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, defaultValue(v.Index(i)))
+		}
+		return values
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int:
+		return v.Int()
+	}
+	return nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jsonschema generates the JSON Schema for the yamlfmt config file
+// from the structs that the config is decoded into.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Schema is the subset of JSON Schema draft-07 that the config schema
+// uses. The fields are in the order they are written out.
+type Schema struct {
+	SchemaURI            string     `json:"$schema,omitempty"`
+	ID                   string     `json:"$id,omitempty"`
+	Title                string     `json:"title,omitempty"`
+	Ref                  string     `json:"$ref,omitempty"`
+	Type                 string     `json:"type,omitempty"`
+	Items                *Schema    `json:"items,omitempty"`
+	Enum                 []string   `json:"enum,omitempty"`
+	Default              any        `json:"default,omitempty"`
+	Description          string     `json:"description,omitempty"`
+	OneOf                []*Schema  `json:"oneOf,omitempty"`
+	Properties           Properties `json:"properties,omitempty"`
+	Required             []string   `json:"required,omitempty"`
+	AdditionalProperties *bool      `json:"additionalProperties,omitempty"`
+	Definitions          Properties `json:"definitions,omitempty"`
+}
+
+// Property is a named schema in an object's properties.
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+// Properties keep the order they were added in when marshalled, so that
+// the schema lists them in the same order as the config structs.
+type Properties []Property
+
+func (ps Properties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, p := range ps {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(p.Name)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		schema, err := json.Marshal(p.Schema)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(schema)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Get returns the schema of the property called name, or nil.
+func (ps Properties) Get(name string) *Schema {
+	for _, p := range ps {
+		if p.Name == name {
+			return p.Schema
+		}
+	}
+	return nil
+}
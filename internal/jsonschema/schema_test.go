@@ -15,15 +15,34 @@
 package jsonschema_test
 
 import (
+	"os"
 	"testing"
 
-	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/yamlfmt/internal/jsonschema"
+	validator "github.com/santhosh-tekuri/jsonschema/v6"
 )
 
+const schemaPath = "../../schema.json"
+
 func TestJSONSchemaIsValid(t *testing.T) {
-	c := jsonschema.NewCompiler()
-	_, err := c.Compile("../../schema.json")
+	c := validator.NewCompiler()
+	_, err := c.Compile(schemaPath)
 	if err != nil {
 		t.Fatalf("JSON schema failed to compile: %v", err)
 	}
 }
+
+func TestJSONSchemaIsUpToDate(t *testing.T) {
+	generated, err := jsonschema.Generate()
+	if err != nil {
+		t.Fatalf("generating JSON schema failed: %v", err)
+	}
+	checkedIn, err := os.ReadFile(schemaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(checkedIn), string(generated)); diff != "" {
+		t.Fatalf("schema.json is out of date, run `make schema` to regenerate it (-checked in +generated):\n%s", diff)
+	}
+}
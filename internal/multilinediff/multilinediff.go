@@ -125,3 +125,38 @@ func (r *Reporter) String() string {
 
 	return strings.Join(diffLines, r.LineSep)
 }
+
+// ChangedLines returns the line numbers, starting from 1, of the lines in
+// b that were added or changed from a.
+func ChangedLines(a, b, lineSep string) []int {
+	reporter := changedLinesReporter{}
+	cmp.Diff(
+		strings.Split(a, lineSep), strings.Split(b, lineSep),
+		cmp.Reporter(&reporter),
+	)
+	return reporter.lines
+}
+
+type changedLinesReporter struct {
+	path  cmp.Path
+	lines []int
+}
+
+func (r *changedLinesReporter) PushStep(ps cmp.PathStep) {
+	r.path = append(r.path, ps)
+}
+
+func (r *changedLinesReporter) Report(rs cmp.Result) {
+	if rs.Equal() {
+		return
+	}
+	if step, ok := r.path.Last().(cmp.SliceIndex); ok {
+		if _, newIndex := step.SplitKeys(); newIndex >= 0 {
+			r.lines = append(r.lines, newIndex+1)
+		}
+	}
+}
+
+func (r *changedLinesReporter) PopStep() {
+	r.path = r.path[:len(r.path)-1]
+}
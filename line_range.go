@@ -0,0 +1,221 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yamlfmt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/yamlfmt/internal/logger"
+	"github.com/google/yamlfmt/pkg/yaml"
+)
+
+// LineRange is an inclusive range of line numbers, starting from 1.
+type LineRange struct {
+	Start int
+	End   int
+}
+
+func (r LineRange) String() string {
+	return fmt.Sprintf("%d:%d", r.Start, r.End)
+}
+
+func (r LineRange) overlaps(start int, end int) bool {
+	return r.Start <= end && start <= r.End
+}
+
+// ParseLineRanges parses comma separated line ranges in the form
+// `start:end`. A single line number is a range of that line alone.
+func ParseLineRanges(s string) ([]LineRange, error) {
+	ranges := []LineRange{}
+	for _, part := range strings.Split(s, ",") {
+		startStr, endStr, isRange := strings.Cut(strings.TrimSpace(part), ":")
+		if !isRange {
+			endStr = startStr
+		}
+		start, err := strconv.Atoi(startStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid line range %q: %w", part, err)
+		}
+		end, err := strconv.Atoi(endStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid line range %q: %w", part, err)
+		}
+		if start < 1 || end < start {
+			return nil, fmt.Errorf("invalid line range %q: lines start at 1 and the end can't be before the start", part)
+		}
+		ranges = append(ranges, LineRange{Start: start, End: end})
+	}
+	return ranges, nil
+}
+
+// LineRangesFromLines groups sorted line numbers into ranges of
+// consecutive lines.
+func LineRangesFromLines(lines []int) []LineRange {
+	ranges := []LineRange{}
+	for _, line := range lines {
+		if len(ranges) > 0 && ranges[len(ranges)-1].End+1 >= line {
+			ranges[len(ranges)-1].End = max(ranges[len(ranges)-1].End, line)
+			continue
+		}
+		ranges = append(ranges, LineRange{Start: line, End: line})
+	}
+	return ranges
+}
+
+// topLevelNode is the lines of a top level mapping entry or sequence
+// item, or of a whole document that isn't a block mapping or sequence.
+type topLevelNode struct {
+	start int
+	end   int
+}
+
+// FormatLineRanges formats only the top level nodes of content that
+// overlap ranges, and leaves the rest of content untouched. Each node is
+// formatted on its own, so a node that can't be, such as one that uses an
+// alias to an anchor in another node, is left untouched as well.
+func FormatLineRanges(formatter Formatter, content []byte, ranges []LineRange) ([]byte, error) {
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	nodes, err := findTopLevelNodes(content, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	var result bytes.Buffer
+	next := 1
+	for _, node := range nodes {
+		if !slices.ContainsFunc(ranges, func(r LineRange) bool { return r.overlaps(node.start, node.end) }) {
+			continue
+		}
+		for ; next < node.start; next++ {
+			result.Write(lines[next-1])
+		}
+		original := bytes.Join(lines[node.start-1:node.end], nil)
+		formatted, err := formatNode(formatter, original)
+		if err != nil {
+			logger.Debug(logger.DebugCodeDiffs, "lines %d:%d can't be formatted on their own, leaving them as is: %v", node.start, node.end, err)
+			formatted = original
+		}
+		result.Write(formatted)
+		next = node.end + 1
+	}
+	for ; next <= len(lines); next++ {
+		result.Write(lines[next-1])
+	}
+	return result.Bytes(), nil
+}
+
+func formatNode(formatter Formatter, original []byte) ([]byte, error) {
+	formatted, err := formatter.Format(original)
+	if err != nil {
+		return nil, err
+	}
+	// The node is in the middle of a document, so a document start that
+	// the formatter adds doesn't belong to it.
+	if !isDocumentMarker(original, "---") && isDocumentMarker(formatted, "---") {
+		_, formatted, _ = bytes.Cut(formatted, []byte("\n"))
+	}
+	if !bytes.HasSuffix(original, []byte("\n")) {
+		formatted = bytes.TrimRight(formatted, "\r\n")
+	}
+	return formatted, nil
+}
+
+// findTopLevelNodes returns the top level nodes of each document in
+// content, in order. Blank lines and unindented comments after a node are
+// left out of it, since they usually belong to the node that follows.
+func findTopLevelNodes(content []byte, lines [][]byte) ([]topLevelNode, error) {
+	starts := []int{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		root := doc.Content[0]
+		switch {
+		case root.Kind == yaml.MappingNode && root.Style&yaml.FlowStyle == 0:
+			for i := 0; i < len(root.Content); i += 2 {
+				starts = append(starts, root.Content[i].Line)
+			}
+		case root.Kind == yaml.SequenceNode && root.Style&yaml.FlowStyle == 0:
+			prev := root.Line - 1
+			for _, item := range root.Content {
+				// An item can start on the line after its dash.
+				start := item.Line
+				for start > prev+1 && !hasDashAt(lines[start-1], root.Column-1) {
+					start--
+				}
+				starts = append(starts, start)
+				prev = start
+			}
+		default:
+			starts = append(starts, root.Line)
+		}
+	}
+
+	nodes := []topLevelNode{}
+	for i, start := range starts {
+		// A node that starts on the same line as a document marker can't
+		// be taken out of its document.
+		if isDocumentMarker(lines[start-1], "---") {
+			continue
+		}
+		end := len(lines)
+		if i+1 < len(starts) {
+			end = starts[i+1] - 1
+		}
+		for line := start + 1; line <= end; line++ {
+			if isDocumentMarker(lines[line-1], "---") || isDocumentMarker(lines[line-1], "...") {
+				end = line - 1
+				break
+			}
+		}
+		for end > start {
+			trimmed := bytes.TrimRight(lines[end-1], " \t\r\n")
+			if len(trimmed) > 0 && trimmed[0] != '#' {
+				break
+			}
+			end--
+		}
+		nodes = append(nodes, topLevelNode{start: start, end: end})
+	}
+	return nodes, nil
+}
+
+func hasDashAt(line []byte, column int) bool {
+	return column < len(line) && line[column] == '-' &&
+		(column+1 == len(line) || bytes.ContainsRune([]byte(" \t\r\n"), rune(line[column+1])))
+}
+
+func isDocumentMarker(line []byte, marker string) bool {
+	rest, ok := bytes.CutPrefix(line, []byte(marker))
+	return ok && (len(rest) == 0 || rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\r' || rest[0] == '\n')
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yamlfmt_test
+
+import (
+	"testing"
+
+	"github.com/google/yamlfmt"
+	"github.com/google/yamlfmt/formatters/basic"
+	"github.com/google/yamlfmt/internal/assert"
+)
+
+func TestParseLineRanges(t *testing.T) {
+	ranges, err := yamlfmt.ParseLineRanges("10:40, 3,5:5")
+	assert.NilErr(t, err)
+	assert.SliceEqual(t, []yamlfmt.LineRange{{Start: 10, End: 40}, {Start: 3, End: 3}, {Start: 5, End: 5}}, ranges)
+
+	for _, invalid := range []string{"", "a:b", "0:3", "5:4", "1:2:3"} {
+		_, err := yamlfmt.ParseLineRanges(invalid)
+		assert.Assert(t, err != nil, "expected an error for %q", invalid)
+	}
+}
+
+func TestLineRangesFromLines(t *testing.T) {
+	ranges := yamlfmt.LineRangesFromLines([]int{1, 2, 3, 7, 9, 10})
+	assert.SliceEqual(t, []yamlfmt.LineRange{{Start: 1, End: 3}, {Start: 7, End: 7}, {Start: 9, End: 10}}, ranges)
+}
+
+func TestFormatLineRanges(t *testing.T) {
+	content := `# header
+first:
+    a:    1
+
+# about second
+second:    [1,   2]
+third:
+    - x
+---
+-    one
+-
+    two
+`
+	testCases := []struct {
+		name     string
+		ranges   []yamlfmt.LineRange
+		expected string
+	}{
+		{
+			name:     "no overlap",
+			ranges:   []yamlfmt.LineRange{{Start: 1, End: 1}, {Start: 4, End: 5}},
+			expected: content,
+		},
+		{
+			name:   "one mapping entry",
+			ranges: []yamlfmt.LineRange{{Start: 3, End: 3}},
+			expected: `# header
+first:
+  a: 1
+
+# about second
+second:    [1,   2]
+third:
+    - x
+---
+-    one
+-
+    two
+`,
+		},
+		{
+			name:   "across entries and documents",
+			ranges: []yamlfmt.LineRange{{Start: 6, End: 10}},
+			expected: `# header
+first:
+    a:    1
+
+# about second
+second: [1, 2]
+third:
+  - x
+---
+- one
+-
+    two
+`,
+		},
+		{
+			name:   "sequence item on the line after its dash",
+			ranges: []yamlfmt.LineRange{{Start: 12, End: 12}},
+			expected: `# header
+first:
+    a:    1
+
+# about second
+second:    [1,   2]
+third:
+    - x
+---
+-    one
+- two
+`,
+		},
+	}
+	formatter, err := (&basic.BasicFormatterFactory{}).NewFormatter(map[string]any{"include_document_start": true})
+	assert.NilErr(t, err)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			formatted, err := yamlfmt.FormatLineRanges(formatter, []byte(content), tc.ranges)
+			assert.NilErr(t, err)
+			assert.Equal(t, tc.expected, string(formatted))
+		})
+	}
+}
+
+func TestFormatLineRangesAliasElsewhere(t *testing.T) {
+	content := "base: &base\n  a: 1\nuses:    *base\nother:    1\n"
+	formatter, err := (&basic.BasicFormatterFactory{}).NewFormatter(map[string]any{})
+	assert.NilErr(t, err)
+	formatted, err := yamlfmt.FormatLineRanges(formatter, []byte(content), []yamlfmt.LineRange{{Start: 3, End: 4}})
+	assert.NilErr(t, err)
+	assert.Equal(t, "base: &base\n  a: 1\nuses:    *base\nother: 1\n", string(formatted))
+}
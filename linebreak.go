@@ -14,13 +14,20 @@
 
 package yamlfmt
 
-import "fmt"
+import (
+	"bytes"
+	"fmt"
+)
 
 type LineBreakStyle string
 
 const (
 	LineBreakStyleLF   LineBreakStyle = "lf"
 	LineBreakStyleCRLF LineBreakStyle = "crlf"
+	// LineBreakStyleAuto is not a line break style on its own; it signals
+	// that the style should be detected from each file's content with
+	// DetectLineBreakStyle.
+	LineBreakStyleAuto LineBreakStyle = "auto"
 )
 
 type UnsupportedLineBreakError struct {
@@ -40,3 +47,29 @@ func (s LineBreakStyle) Separator() (string, error) {
 	}
 	return "", UnsupportedLineBreakError{style: s}
 }
+
+// DetectLineBreakStyle counts the LF and CRLF line breaks in content and
+// returns the style used by the majority of lines. Ties, including content
+// with no line breaks at all, resolve to LF. The second return value reports
+// whether content mixes both styles.
+func DetectLineBreakStyle(content []byte) (LineBreakStyle, bool) {
+	total := bytes.Count(content, []byte("\n"))
+	crlf := bytes.Count(content, []byte("\r\n"))
+	lf := total - crlf
+	mixed := crlf > 0 && lf > 0
+	if crlf > lf {
+		return LineBreakStyleCRLF, mixed
+	}
+	return LineBreakStyleLF, mixed
+}
+
+// withLineBreaksOf converts the line breaks of content to the style of
+// the line break that line ends with. It is used for YAML embedded in
+// another file, which is left as it is around the YAML.
+func withLineBreaksOf(content []byte, line []byte) []byte {
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+	if bytes.HasSuffix(line, []byte("\r\n")) {
+		content = bytes.ReplaceAll(content, []byte("\n"), []byte("\r\n"))
+	}
+	return content
+}
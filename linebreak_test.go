@@ -0,0 +1,67 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yamlfmt_test
+
+import (
+	"testing"
+
+	"github.com/google/yamlfmt"
+	"github.com/google/yamlfmt/internal/assert"
+)
+
+func TestDetectLineBreakStyle(t *testing.T) {
+	testCases := []struct {
+		name          string
+		content       string
+		expectedStyle yamlfmt.LineBreakStyle
+		expectedMixed bool
+	}{
+		{
+			name:          "no line breaks",
+			content:       "a: 1",
+			expectedStyle: yamlfmt.LineBreakStyleLF,
+		},
+		{
+			name:          "only lf",
+			content:       "a: 1\nb: 2\n",
+			expectedStyle: yamlfmt.LineBreakStyleLF,
+		},
+		{
+			name:          "only crlf",
+			content:       "a: 1\r\nb: 2\r\n",
+			expectedStyle: yamlfmt.LineBreakStyleCRLF,
+		},
+		{
+			name:          "mostly crlf",
+			content:       "a: 1\r\nb: 2\r\nc: 3\n",
+			expectedStyle: yamlfmt.LineBreakStyleCRLF,
+			expectedMixed: true,
+		},
+		{
+			name:          "tie goes to lf",
+			content:       "a: 1\r\nb: 2\n",
+			expectedStyle: yamlfmt.LineBreakStyleLF,
+			expectedMixed: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			style, mixed := yamlfmt.DetectLineBreakStyle([]byte(tc.content))
+			assert.Equal(t, tc.expectedStyle, style)
+			assert.Equal(t, tc.expectedMixed, mixed)
+		})
+	}
+}
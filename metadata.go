@@ -17,10 +17,12 @@ package yamlfmt
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"unicode"
 
 	"github.com/google/yamlfmt/internal/collections"
+	"github.com/google/yamlfmt/pkg/yaml"
 )
 
 const MetadataIdentifier = "!yamlfmt!"
@@ -29,22 +31,47 @@ type MetadataType string
 
 const (
 	MetadataIgnore MetadataType = "ignore"
+	// MetadataOff and MetadataOn comments mark a region of lines that the
+	// formatter leaves as written, except that the region is re-indented
+	// as a block if the indentation around it changes.
+	MetadataOff MetadataType = "off"
+	MetadataOn  MetadataType = "on"
+	// MetadataIgnoreNext comments mark the node after them to be left as
+	// written, re-indented like an off region.
+	MetadataIgnoreNext MetadataType = "ignore-next"
+	// MetadataIgnoreDocument comments mark the document they are in to be
+	// left as written.
+	MetadataIgnoreDocument MetadataType = "ignore-document"
+	// MetadataSet comments, in the form `!yamlfmt!:set:key=value`, set a
+	// formatter setting for the file they are in.
+	MetadataSet MetadataType = "set"
 )
 
 func IsMetadataType(mdValueStr string) bool {
 	mdTypes := collections.Set[MetadataType]{}
 	mdTypes.Add(MetadataIgnore)
+	mdTypes.Add(MetadataOff)
+	mdTypes.Add(MetadataOn)
+	mdTypes.Add(MetadataIgnoreNext)
+	mdTypes.Add(MetadataIgnoreDocument)
+	mdTypes.Add(MetadataSet)
 	return mdTypes.Contains(MetadataType(mdValueStr))
 }
 
 type Metadata struct {
 	Type    MetadataType
 	LineNum int
+	// The setting and its value, and the line they are on, for
+	// MetadataSet.
+	Key   string
+	Value string
+	Line  string
 }
 
 var (
-	ErrMalformedMetadata    = errors.New("metadata: malformed string")
-	ErrUnrecognizedMetadata = errors.New("metadata: unrecognized type")
+	ErrMalformedMetadata      = errors.New("metadata: malformed string")
+	ErrUnrecognizedMetadata   = errors.New("metadata: unrecognized type")
+	ErrInvalidMetadataSetting = errors.New("metadata: invalid setting")
 )
 
 type MetadataError struct {
@@ -79,8 +106,24 @@ func ReadMetadata(content []byte, path string) (collections.Set[Metadata], colle
 			continue
 		}
 		mdStr := scanMetadata(line, mdidIndex)
-		mdComponents := strings.Split(mdStr, ":")
-		if len(mdComponents) != 2 {
+		// Only the set type has a third component, and its value can
+		// contain colons.
+		mdComponents := strings.SplitN(mdStr, ":", 3)
+		if len(mdComponents) == 3 && mdComponents[1] == string(MetadataSet) {
+			key, value, ok := strings.Cut(mdComponents[2], "=")
+			if !ok || key == "" {
+				mdErrs = append(mdErrs, &MetadataError{
+					path:    path,
+					lineNum: i + 1,
+					err:     ErrMalformedMetadata,
+					lineStr: line,
+				})
+				continue
+			}
+			metadata.Add(Metadata{LineNum: i + 1, Type: MetadataSet, Key: key, Value: value, Line: line})
+			continue
+		}
+		if len(mdComponents) != 2 || mdComponents[1] == string(MetadataSet) {
 			mdErrs = append(mdErrs, &MetadataError{
 				path:    path,
 				lineNum: i + 1,
@@ -103,6 +146,61 @@ func ReadMetadata(content []byte, path string) (collections.Set[Metadata], colle
 	return metadata, mdErrs
 }
 
+// MetadataSettings reads the formatter settings that `!yamlfmt!:set`
+// metadata sets in content. Values are parsed as yaml scalars, so
+// `max_line_length=0` sets a number and `eof_newline=true` a bool. Each
+// setting is checked with validate, and an invalid one is left out and
+// reported as a MetadataError. When a key is set more than once, the
+// last one wins.
+func MetadataSettings(content []byte, path string, validate func(key string, value any) error) (map[string]any, collections.Errors) {
+	metadata, mdErrs := ReadMetadata(content, path)
+	settings, settingErrs := CheckMetadataSettings(SetMetadata(metadata), path, validate)
+	return settings, append(mdErrs, settingErrs...)
+}
+
+// SetMetadata returns the `!yamlfmt!:set` metadata of metadata, in the
+// order of the lines they are on.
+func SetMetadata(metadata collections.Set[Metadata]) []Metadata {
+	setMetadata := []Metadata{}
+	for md := range metadata {
+		if md.Type == MetadataSet {
+			setMetadata = append(setMetadata, md)
+		}
+	}
+	slices.SortFunc(setMetadata, func(a, b Metadata) int {
+		return a.LineNum - b.LineNum
+	})
+	return setMetadata
+}
+
+// CheckMetadataSettings is MetadataSettings for the `!yamlfmt!:set`
+// metadata that SetMetadata found in the file at path.
+func CheckMetadataSettings(setMetadata []Metadata, path string, validate func(key string, value any) error) (map[string]any, collections.Errors) {
+	settings := map[string]any{}
+	mdErrs := collections.Errors{}
+	for _, md := range setMetadata {
+		var value any
+		err := errors.New("missing value")
+		if md.Value != "" {
+			err = yaml.Unmarshal([]byte(md.Value), &value)
+		}
+		if err == nil {
+			err = validate(md.Key, value)
+		}
+		if err != nil {
+			mdErrs = append(mdErrs, &MetadataError{
+				path:    path,
+				lineNum: md.LineNum,
+				err:     fmt.Errorf("%w %q: %v", ErrInvalidMetadataSetting, md.Key, err),
+				lineStr: md.Line,
+			})
+			continue
+		}
+		settings[md.Key] = value
+	}
+	return settings, mdErrs
+}
+
 func scanMetadata(line string, index int) string {
 	mdBytes := []byte{}
 	i := index
@@ -16,6 +16,7 @@ package yamlfmt_test
 
 import (
 	"errors"
+	"maps"
 	"testing"
 
 	"github.com/google/yamlfmt"
@@ -52,6 +53,41 @@ func TestReadMetadata(t *testing.T) {
 			},
 			errCheck: checkErrNil,
 		},
+		{
+			name:    "has region metadata",
+			content: "# !yamlfmt!:off\na: 1\n# !yamlfmt!:on\n# !yamlfmt!:ignore-next\nb: 2\n---\n# !yamlfmt!:ignore-document",
+			expected: collections.Set[yamlfmt.Metadata]{
+				{Type: yamlfmt.MetadataOff, LineNum: 1}:            {},
+				{Type: yamlfmt.MetadataOn, LineNum: 3}:             {},
+				{Type: yamlfmt.MetadataIgnoreNext, LineNum: 4}:     {},
+				{Type: yamlfmt.MetadataIgnoreDocument, LineNum: 7}: {},
+			},
+			errCheck: checkErrNil,
+		},
+		{
+			name:    "has set metadata",
+			content: "# !yamlfmt!:set:max_line_length=0\n# !yamlfmt!:set:line_ending=a:b",
+			expected: collections.Set[yamlfmt.Metadata]{
+				{Type: yamlfmt.MetadataSet, LineNum: 1, Key: "max_line_length", Value: "0", Line: "# !yamlfmt!:set:max_line_length=0"}: {},
+				{Type: yamlfmt.MetadataSet, LineNum: 2, Key: "line_ending", Value: "a:b", Line: "# !yamlfmt!:set:line_ending=a:b"}:     {},
+			},
+			errCheck: checkErrNil,
+		},
+		{
+			name:     "has set metadata without a setting",
+			content:  "# !yamlfmt!:set\n# !yamlfmt!:set:max_line_length",
+			expected: collections.Set[yamlfmt.Metadata]{},
+			errCheck: func(t *testing.T, errs collections.Errors) {
+				if len(errs) != 2 {
+					t.Fatalf("expected 2 errors, got %d:\n%v", len(errs), errs.Combine())
+				}
+				for _, err := range errs {
+					if errors.Unwrap(err) != yamlfmt.ErrMalformedMetadata {
+						t.Fatalf("expected ErrMalformedMetadata, got: %v", err)
+					}
+				}
+			},
+		},
 		{
 			name:     "has bad metadata",
 			content:  "# !yamlfmt!fjghgh",
@@ -91,3 +127,37 @@ func TestReadMetadata(t *testing.T) {
 		})
 	}
 }
+
+func TestMetadataSettings(t *testing.T) {
+	content := `# !yamlfmt!:set:max_line_length=0
+# !yamlfmt!:set:eof_newline=true
+# !yamlfmt!:set:indent=2
+# !yamlfmt!:set:indent=4
+# !yamlfmt!:set:unknown=1
+# !yamlfmt!:set:indent=
+a: 1
+`
+	validate := func(key string, value any) error {
+		if key == "unknown" {
+			return errors.New("unknown key")
+		}
+		return nil
+	}
+	settings, errs := yamlfmt.MetadataSettings([]byte(content), "test.yaml", validate)
+	expected := map[string]any{
+		"max_line_length": 0,
+		"eof_newline":     true,
+		"indent":          4,
+	}
+	if !maps.Equal(settings, expected) {
+		t.Fatalf("Mismatched settings:\nexpected: %v\ngot: %v", expected, settings)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %d:\n%v", len(errs), errs.Combine())
+	}
+	for _, err := range errs {
+		if !errors.Is(err, yamlfmt.ErrInvalidMetadataSetting) {
+			t.Fatalf("expected ErrInvalidMetadataSetting, got: %v", err)
+		}
+	}
+}
@@ -23,11 +23,14 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/bmatcuk/doublestar/v4"
 	"github.com/google/yamlfmt/internal/collections"
+	"github.com/google/yamlfmt/internal/git"
 	"github.com/google/yamlfmt/internal/logger"
+	"github.com/google/yamlfmt/internal/multilinediff"
 	ignore "github.com/sabhiram/go-gitignore"
 )
 
@@ -37,21 +40,61 @@ const (
 	MatchTypeStandard   MatchType = "standard"
 	MatchTypeDoublestar MatchType = "doublestar"
 	MatchTypeGitignore  MatchType = "gitignore"
+	MatchTypeGit        MatchType = "git"
 )
 
 type PathCollector interface {
 	CollectPaths() ([]string, error)
 }
 
+// PathExplainer is implemented by path collectors that can describe why
+// a path is or isn't collected.
+type PathExplainer interface {
+	ExplainPath(path string) (collected bool, reason string, err error)
+}
+
+// ExcludedPath is a path that was left out of formatting, and why.
+type ExcludedPath struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// ExcludedPathReporter is implemented by path collectors that can report
+// the paths that their exclude patterns left out of the last call to
+// CollectPaths.
+type ExcludedPathReporter interface {
+	ExcludedPaths() []ExcludedPath
+}
+
+// PathFilter returns the paths that are kept of paths, like
+// ExcludeWithGitignore does.
+type PathFilter func(paths []string) ([]string, error)
+
 type FilepathCollector struct {
 	Include    []string
 	Exclude    []string
 	Extensions []string
+	// Files in include directories whose base name matches one of these
+	// patterns are collected whatever their extension.
+	Filenames []string
+	// When set, files without an extension in include directories are
+	// collected if their content looks like YAML. See SniffYaml.
+	SniffContent bool
+	// Filters for the files whose content would be sniffed, which run
+	// before they are read. The exclude patterns are always checked first.
+	SniffFilters []PathFilter
+	Symlinks     SymlinkPolicy
+
+	excluded []ExcludedPath
 }
 
 func (c *FilepathCollector) CollectPaths() ([]string, error) {
 	logger.Debug(logger.DebugCodePaths, "using file path matching. include patterns: %s", c.Include)
+	if err := validateFilenames(c.Filenames); err != nil {
+		return nil, err
+	}
 	pathsFound := []string{}
+	sniffCandidates := []string{}
 	for _, inclPath := range c.Include {
 		info, err := os.Stat(inclPath)
 		if err != nil {
@@ -64,88 +107,217 @@ func (c *FilepathCollector) CollectPaths() ([]string, error) {
 			pathsFound = append(pathsFound, inclPath)
 			continue
 		}
-		paths, err := c.walkDirectoryForYaml(inclPath)
+		paths, candidates, err := c.walkDirectoryForYaml(inclPath)
 		if err != nil {
-			fmt.Printf("received errors walking %s:\n%v\n", inclPath, err)
+			fmt.Fprintf(os.Stderr, "received errors walking %s:\n%v\n", inclPath, err)
 		}
 		pathsFound = append(pathsFound, paths...)
+		sniffCandidates = append(sniffCandidates, candidates...)
 	}
 	logger.Debug(logger.DebugCodePaths, "found paths: %s", pathsFound)
 
-	pathsFoundSet := collections.SliceToSet(pathsFound)
-	pathsToFormat := collections.SliceToSet(pathsFound)
-	for _, exclPath := range c.Exclude {
-		info, err := os.Stat(exclPath)
+	excludes, err := c.statExcludes()
+	if err != nil {
+		return nil, err
+	}
+	pathsToFormat := collections.Set[string]{}
+	c.excluded = []ExcludedPath{}
+	for foundPath := range collections.SliceToSet(pathsFound) {
+		if reason := excludes.excludedBy(foundPath); reason != "" {
+			logger.Debug(logger.DebugCodePaths, "excluding %s, %s", foundPath, reason)
+			c.excluded = append(c.excluded, ExcludedPath{Path: foundPath, Reason: reason})
+			continue
+		}
+		pathsToFormat.Add(foundPath)
+	}
+
+	// Only the candidates that wouldn't be excluded are read.
+	sniffCandidates = slices.DeleteFunc(sniffCandidates, func(path string) bool {
+		return pathsToFormat.Contains(path) || excludes.excludedBy(path) != ""
+	})
+	sniffed, err := sniffPaths(sniffCandidates, c.SniffFilters)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range sniffed {
+		pathsToFormat.Add(path)
+	}
+
+	pathsToFormatSlice, skipped, err := ApplySymlinkPolicy(c.Symlinks, pathsToFormat.ToSlice())
+	if err != nil {
+		return nil, err
+	}
+	warnSkippedExplicitPaths(c.Include, skipped)
+	c.excluded = append(c.excluded, skipped...)
+	logger.Debug(logger.DebugCodePaths, "paths to format: %s", pathsToFormatSlice)
+	return pathsToFormatSlice, nil
+}
+
+// ExcludedPaths implements the ExcludedPathReporter interface.
+func (c *FilepathCollector) ExcludedPaths() []ExcludedPath {
+	return c.excluded
+}
+
+// ExplainPath follows the same rules as CollectPaths for a single path.
+func (c *FilepathCollector) ExplainPath(path string) (bool, string, error) {
+	if err := validateFilenames(c.Filenames); err != nil {
+		return false, "", err
+	}
+	path = filepath.Clean(path)
+	included := ""
+	for _, inclPath := range c.Include {
+		info, err := os.Stat(inclPath)
 		if err != nil {
 			if !os.IsNotExist(err) {
-				return nil, err
+				return false, "", err
 			}
 			continue
 		}
-
-		if info.IsDir() {
-			logger.Debug(logger.DebugCodePaths, "for exclude dir: %s", exclPath)
-			for foundPath := range pathsFoundSet {
-				if strings.HasPrefix(foundPath, exclPath) {
-					logger.Debug(logger.DebugCodePaths, "excluding %s", foundPath)
-					pathsToFormat.Remove(foundPath)
-				}
+		if !info.IsDir() {
+			if filepath.Clean(inclPath) == path {
+				included = fmt.Sprintf("matched by include %q", inclPath)
+				break
 			}
-		} else {
-			logger.Debug(logger.DebugCodePaths, "for exclude file: %s", exclPath)
-			removed := pathsToFormat.Remove(exclPath)
-			if removed {
-				logger.Debug(logger.DebugCodePaths, "found in paths, excluding")
+			continue
+		}
+		within, err := isWithinDir(path, inclPath)
+		if err != nil {
+			return false, "", err
+		}
+		if !within {
+			continue
+		}
+		included = fmt.Sprintf("in include directory %q", inclPath)
+		if inGitDir(path, inclPath) {
+			return false, fmt.Sprintf("%s, but in a %s directory, which isn't walked", included, gitDirName), nil
+		}
+		if !c.extensionMatches(filepath.Base(path)) {
+			reason := matchWithoutExtension(path, c.Filenames, c.SniffContent)
+			if reason == "" {
+				return false, fmt.Sprintf("%s, but doesn't have one of the extensions %v", included, c.Extensions), nil
 			}
+			included += ", " + reason
 		}
+		break
+	}
+	if included == "" {
+		return false, fmt.Sprintf("not matched by any include path %v", c.Include), nil
 	}
 
-	pathsToFormatSlice := pathsToFormat.ToSlice()
-	logger.Debug(logger.DebugCodePaths, "paths to format: %s", pathsToFormat)
-	return pathsToFormatSlice, nil
+	excludes, err := c.statExcludes()
+	if err != nil {
+		return false, "", err
+	}
+	if reason := excludes.excludedBy(path); reason != "" {
+		return false, fmt.Sprintf("%s, but %s", included, reason), nil
+	}
+	return true, included, nil
 }
 
-func (c *FilepathCollector) walkDirectoryForYaml(dir string) ([]string, error) {
-	var paths []string
-	var walkErrs []error
-	err := filepath.Walk(dir, func(path string, info fs.FileInfo, err error) error {
-		if err != nil {
-			walkErrs = append(
-				walkErrs,
-				fmt.Errorf("error for path %s: %v", path, err),
-			)
-			return nil
-		}
-		if info == nil {
-			// Defensive programming, but this case should never hit.
-			walkErrs = append(
-				walkErrs,
-				fmt.Errorf("error for path %s: %v", path, errors.New("error for pathnil file info, please report a GitHub issue if you see this failure")),
-			)
-			return nil
-		}
+// inGitDir reports whether path is in a `.git` directory below dir.
+func inGitDir(path string, dir string) bool {
+	rel, err := filepath.Rel(dir, filepath.Dir(path))
+	if err != nil {
+		return false
+	}
+	return slices.Contains(strings.Split(rel, string(filepath.Separator)), gitDirName)
+}
+
+func isWithinDir(path string, dir string) (bool, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false, err
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil {
+		return false, nil
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)), nil
+}
 
-		if info.IsDir() {
+// walkDirectoryForYaml returns the files in dir that are collected by
+// their extension or name, and the ones whose content decides it. `.git`
+// directories aren't walked.
+func (c *FilepathCollector) walkDirectoryForYaml(dir string) ([]string, []string, error) {
+	walker, err := newSymlinkWalker(c.Symlinks)
+	if err != nil {
+		return nil, nil, err
+	}
+	var paths, sniffCandidates []string
+	err = walker.walkDir(dir, func(path string, isDir bool) error {
+		if isDir {
+			if filepath.Base(path) == gitDirName {
+				return fs.SkipDir
+			}
 			return nil
 		}
-
-		if c.extensionMatches(info.Name()) {
+		if c.extensionMatches(filepath.Base(path)) {
+			paths = append(paths, path)
+		} else if reason := matchFilename(path, c.Filenames); reason != "" {
+			logger.Debug(logger.DebugCodePaths, "collecting %s, %s", path, reason)
 			paths = append(paths, path)
+		} else if needsSniffing(path, c.SniffContent) {
+			sniffCandidates = append(sniffCandidates, path)
 		}
-
 		return nil
 	})
-	// We join the errors for all paths and return that, so filepath.Walk's error should always be nil.
-	// This is a defensive programming maneuver against that scenario that should cause yamlfmt
-	// to surface the error and no-op for the provided path.
-	if err != nil {
-		return []string{}, err
+	return paths, sniffCandidates, err
+}
+
+// gitDirName is the name of the directories that git keeps its data in,
+// which are never walked for files to format.
+const gitDirName = ".git"
+
+// pathExcludes are the exclude paths of a FilepathCollector that exist.
+type pathExcludes []struct {
+	path  string
+	isDir bool
+}
+
+func (c *FilepathCollector) statExcludes() (pathExcludes, error) {
+	excludes := pathExcludes{}
+	for _, exclPath := range c.Exclude {
+		info, err := os.Stat(exclPath)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return nil, err
+			}
+			continue
+		}
+		excludes = append(excludes, struct {
+			path  string
+			isDir bool
+		}{path: exclPath, isDir: info.IsDir()})
+	}
+	return excludes, nil
+}
+
+// excludedBy describes the first exclude path that excludes path, or
+// returns an empty string if none do.
+func (e pathExcludes) excludedBy(path string) string {
+	for _, excl := range e {
+		if excl.isDir && strings.HasPrefix(path, excl.path) {
+			return fmt.Sprintf("in exclude directory %q", excl.path)
+		}
+		if !excl.isDir && path == excl.path {
+			return fmt.Sprintf("matched by exclude %q", excl.path)
+		}
 	}
-	return paths, errors.Join(walkErrs...)
+	return ""
 }
 
 func (c *FilepathCollector) extensionMatches(name string) bool {
-	for _, ext := range c.Extensions {
+	return ExtensionMatches(name, c.Extensions)
+}
+
+// ExtensionMatches reports whether name ends with one of the extensions,
+// which may be given with or without a leading dot.
+func ExtensionMatches(name string, extensions []string) bool {
+	for _, ext := range extensions {
 		// Users may specify "yaml", but we only want to match ".yaml", not "buyaml".
 		if !strings.HasPrefix(ext, ".") {
 			ext = "." + ext
@@ -159,8 +331,11 @@ func (c *FilepathCollector) extensionMatches(name string) bool {
 }
 
 type DoublestarCollector struct {
-	Include []string
-	Exclude []string
+	Include  []string
+	Exclude  []string
+	Symlinks SymlinkPolicy
+
+	excluded []ExcludedPath
 }
 
 func (c *DoublestarCollector) CollectPaths() ([]string, error) {
@@ -168,7 +343,7 @@ func (c *DoublestarCollector) CollectPaths() ([]string, error) {
 	includedPaths := []string{}
 	for _, pattern := range c.Include {
 		logger.Debug(logger.DebugCodePaths, "trying pattern: %s", pattern)
-		globMatches, err := doublestar.FilepathGlob(pattern)
+		globMatches, err := c.glob(pattern)
 		if err != nil {
 			return nil, err
 		}
@@ -177,6 +352,8 @@ func (c *DoublestarCollector) CollectPaths() ([]string, error) {
 	}
 
 	pathsToFormatSet := collections.Set[string]{}
+	excludedSet := collections.Set[string]{}
+	c.excluded = []ExcludedPath{}
 	for _, path := range includedPaths {
 		if len(c.Exclude) == 0 {
 			pathsToFormatSet.Add(path)
@@ -192,6 +369,14 @@ func (c *DoublestarCollector) CollectPaths() ([]string, error) {
 			if match {
 				logger.Debug(logger.DebugCodePaths, "pattern %s matched, excluding", pattern)
 				excluded = true
+				// Include patterns can match the same path more than once.
+				if !excludedSet.Contains(path) {
+					excludedSet.Add(path)
+					c.excluded = append(c.excluded, ExcludedPath{
+						Path:   path,
+						Reason: fmt.Sprintf("matched by exclude pattern %q", pattern),
+					})
+				}
 				break
 			}
 			logger.Debug(logger.DebugCodePaths, "pattern %s did not match path", pattern)
@@ -202,11 +387,96 @@ func (c *DoublestarCollector) CollectPaths() ([]string, error) {
 		}
 	}
 
-	pathsToFormat := pathsToFormatSet.ToSlice()
+	pathsToFormat, skipped, err := ApplySymlinkPolicy(c.Symlinks, pathsToFormatSet.ToSlice())
+	if err != nil {
+		return nil, err
+	}
+	// Patterns without wildcards name a file explicitly.
+	warnSkippedExplicitPaths(c.Include, skipped)
+	c.excluded = append(c.excluded, skipped...)
 	logger.Debug(logger.DebugCodePaths, "paths to format: %s", pathsToFormat)
 	return pathsToFormat, nil
 }
 
+// glob returns the files that match pattern. Rather than using
+// doublestar.FilepathGlob, which follows every symlink and has no loop
+// detection, the directory that the pattern starts from is walked with
+// the symlink policy.
+func (c *DoublestarCollector) glob(pattern string) ([]string, error) {
+	pattern = filepath.Clean(pattern)
+	if !doublestar.ValidatePathPattern(pattern) {
+		return nil, doublestar.ErrBadPattern
+	}
+	base, _ := doublestar.SplitPattern(filepath.ToSlash(pattern))
+	base = filepath.FromSlash(base)
+	if filepath.ToSlash(base) == filepath.ToSlash(pattern) {
+		// The pattern has no wildcards, so it can only match itself.
+		if info, err := os.Stat(pattern); err != nil || info.IsDir() {
+			return nil, nil
+		}
+		return []string{pattern}, nil
+	}
+	if info, err := os.Stat(base); err != nil || !info.IsDir() {
+		return nil, nil
+	}
+	walker, err := newSymlinkWalker(c.Symlinks)
+	if err != nil {
+		return nil, err
+	}
+	matches := []string{}
+	err = walker.walkDir(base, func(path string, isDir bool) error {
+		if isDir {
+			return nil
+		}
+		match, err := doublestar.PathMatch(pattern, path)
+		if err != nil {
+			return err
+		}
+		if match {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "received errors walking %s:\n%v\n", base, err)
+	}
+	return matches, nil
+}
+
+// ExcludedPaths implements the ExcludedPathReporter interface.
+func (c *DoublestarCollector) ExcludedPaths() []ExcludedPath {
+	return c.excluded
+}
+
+// ExplainPath follows the same rules as CollectPaths for a single path.
+func (c *DoublestarCollector) ExplainPath(path string) (bool, string, error) {
+	path = filepath.Clean(path)
+	included := ""
+	for _, pattern := range c.Include {
+		match, err := doublestar.PathMatch(filepath.Clean(pattern), path)
+		if err != nil {
+			return false, "", err
+		}
+		if match {
+			included = fmt.Sprintf("matched by include pattern %q", pattern)
+			break
+		}
+	}
+	if included == "" {
+		return false, fmt.Sprintf("not matched by any include pattern %v", c.Include), nil
+	}
+	for _, pattern := range c.Exclude {
+		match, err := doublestar.PathMatch(filepath.Clean(pattern), path)
+		if err != nil {
+			return false, "", err
+		}
+		if match {
+			return false, fmt.Sprintf("%s, but matched by exclude pattern %q", included, pattern), nil
+		}
+	}
+	return true, included, nil
+}
+
 func findGitIgnorePath(gitignorePath string) (string, error) {
 	// if path is absolute, check if exists and return
 	if filepath.IsAbs(gitignorePath) {
@@ -243,7 +513,151 @@ func findGitIgnorePath(gitignorePath string) (string, error) {
 	}
 }
 
+// ExcludeWithGitignore removes the paths ignored by gitignore files. When
+// gitignorePath is only a file name, every file with that name applies to
+// the paths in its own directory and below, like `.gitignore` files do in
+// git. Otherwise it is the path of a single gitignore file, which is found
+// by searching up to the git root if it is relative.
 func ExcludeWithGitignore(gitignorePath string, paths []string) ([]string, error) {
+	if isSingleIgnoreFile(gitignorePath) {
+		return excludeWithGitignoreFile(gitignorePath, paths)
+	}
+	files, err := newIgnoreFiles(gitignorePath)
+	if err != nil {
+		return nil, err
+	}
+	return files.exclude(paths)
+}
+
+// ExplainGitignore reports whether the gitignore files would exclude path,
+// and the pattern that decides it.
+func ExplainGitignore(gitignorePath string, path string) (bool, string, error) {
+	if isSingleIgnoreFile(gitignorePath) {
+		return explainGitignoreFile(gitignorePath, path)
+	}
+	files, err := newIgnoreFiles(gitignorePath)
+	if err != nil {
+		return false, "", err
+	}
+	return files.explain(path)
+}
+
+// YamlfmtIgnoreFileName is the name of the files that exclude paths from
+// formatting, using the gitignore syntax.
+const YamlfmtIgnoreFileName = ".yamlfmtignore"
+
+// ExcludeWithYamlfmtIgnore removes the paths ignored by the `.yamlfmtignore`
+// files that apply to them.
+func ExcludeWithYamlfmtIgnore(paths []string) ([]string, error) {
+	files, err := newIgnoreFiles(YamlfmtIgnoreFileName)
+	if err != nil {
+		return nil, err
+	}
+	return files.exclude(paths)
+}
+
+// ExplainYamlfmtIgnore reports whether the `.yamlfmtignore` files would
+// exclude path, and the pattern that decides it.
+func ExplainYamlfmtIgnore(path string) (bool, string, error) {
+	files, err := newIgnoreFiles(YamlfmtIgnoreFileName)
+	if err != nil {
+		return false, "", err
+	}
+	return files.explain(path)
+}
+
+func isSingleIgnoreFile(ignorePath string) bool {
+	return filepath.IsAbs(ignorePath) || filepath.Base(ignorePath) != ignorePath
+}
+
+// ignoreFiles matches paths against the ignore files with the same name in
+// each directory of a tree.
+type ignoreFiles struct {
+	fileName string
+	root     string
+	ignorer  *git.Ignorer
+}
+
+// newIgnoreFiles uses the files named fileName in the git work tree that
+// the working directory is in, or in the working directory if it isn't in
+// a git repository.
+func newIgnoreFiles(fileName string) (*ignoreFiles, error) {
+	root, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	repo, err := git.FindRepo(root)
+	if err == nil {
+		root = repo.WorkTree
+	} else if !errors.Is(err, git.ErrNotARepo) {
+		return nil, err
+	}
+	logger.Debug(logger.DebugCodePaths, "using %s files in %s", fileName, root)
+	return &ignoreFiles{
+		fileName: fileName,
+		root:     root,
+		ignorer:  git.NewIgnorer(root, fileName),
+	}, nil
+}
+
+// match returns the pattern that decides whether path is ignored, or nil
+// if no pattern matches it or it is outside of the tree.
+func (f *ignoreFiles) match(path string) (*git.IgnorePattern, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	relPath, err := filepath.Rel(f.root, absPath)
+	if err != nil {
+		return nil, nil
+	}
+	relPath = filepath.ToSlash(relPath)
+	if relPath == "." || relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return nil, nil
+	}
+	info, err := os.Stat(path)
+	isDir := err == nil && info.IsDir()
+	return f.ignorer.MatchWithParents(relPath, isDir)
+}
+
+func (f *ignoreFiles) exclude(paths []string) ([]string, error) {
+	pathsToFormat := []string{}
+	for _, path := range paths {
+		pattern, err := f.match(path)
+		if err != nil {
+			return nil, err
+		}
+		if pattern != nil && !pattern.Negate {
+			logger.Debug(logger.DebugCodePaths, "pattern %s in %s matches %s, excluding", pattern.Line, pattern.Source, path)
+			continue
+		}
+		pathsToFormat = append(pathsToFormat, path)
+	}
+	logger.Debug(logger.DebugCodePaths, "paths to format: %s", pathsToFormat)
+	return pathsToFormat, nil
+}
+
+func (f *ignoreFiles) explain(path string) (bool, string, error) {
+	pattern, err := f.match(path)
+	if err != nil {
+		return false, "", err
+	}
+	if pattern == nil {
+		return false, fmt.Sprintf("not matched by any pattern in a %s file", f.fileName), nil
+	}
+	source := pattern.Source
+	if wd, err := os.Getwd(); err == nil {
+		if relSource, err := filepath.Rel(wd, source); err == nil {
+			source = relSource
+		}
+	}
+	if pattern.Negate {
+		return false, fmt.Sprintf("negated by pattern %q (%s:%d)", pattern.Line, source, pattern.LineNo), nil
+	}
+	return true, fmt.Sprintf("matched by pattern %q (%s:%d)", pattern.Line, source, pattern.LineNo), nil
+}
+
+func excludeWithGitignoreFile(gitignorePath string, paths []string) ([]string, error) {
 	gitignorePath, err := findGitIgnorePath(gitignorePath)
 	if err != nil {
 		return nil, err
@@ -265,12 +679,206 @@ func ExcludeWithGitignore(gitignorePath string, paths []string) ([]string, error
 	return pathsToFormat, nil
 }
 
+func explainGitignoreFile(gitignorePath string, path string) (bool, string, error) {
+	gitignorePath, err := findGitIgnorePath(gitignorePath)
+	if err != nil {
+		return false, "", err
+	}
+	ignorer, err := ignore.CompileIgnoreFile(gitignorePath)
+	if err != nil {
+		return false, "", err
+	}
+	ok, pattern := ignorer.MatchesPathHow(path)
+	if pattern == nil {
+		return false, fmt.Sprintf("not matched by any pattern in %s", gitignorePath), nil
+	}
+	if !ok {
+		return false, fmt.Sprintf("negated by pattern %q (%s:%d)", pattern.Line, gitignorePath, pattern.LineNo), nil
+	}
+	return true, fmt.Sprintf("matched by pattern %q (%s:%d)", pattern.Line, gitignorePath, pattern.LineNo), nil
+}
+
+// changedSince compares files in the working directory to the files in a
+// commit of the git repository they're in.
+type changedSince struct {
+	repo   *git.Repo
+	rev    string
+	commit string
+	files  map[string]git.TreeEntry
+}
+
+func newChangedSince(rev string) (*changedSince, error) {
+	repo, err := git.FindRepo(".")
+	if err != nil {
+		return nil, err
+	}
+	commit, err := repo.ResolveCommit(rev)
+	if err != nil {
+		return nil, err
+	}
+	files, err := repo.CommitFiles(commit)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug(logger.DebugCodePaths, "comparing paths to %s (%s)", rev, commit)
+	return &changedSince{repo: repo, rev: rev, commit: commit, files: files}, nil
+}
+
+// entry returns the slash separated path of path in the work tree, and
+// the file at that path in the commit, if there is one.
+func (cs *changedSince) entry(path string) (string, git.TreeEntry, bool, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", git.TreeEntry{}, false, err
+	}
+	relPath, err := filepath.Rel(cs.repo.WorkTree, absPath)
+	if err != nil {
+		return "", git.TreeEntry{}, false, err
+	}
+	relPath = filepath.ToSlash(relPath)
+	if relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return "", git.TreeEntry{}, false, fmt.Errorf("%s is outside of the git repository in %s", path, cs.repo.WorkTree)
+	}
+	entry, ok := cs.files[relPath]
+	return relPath, entry, ok, nil
+}
+
+// readFile reads the file at path with its line endings converted the way
+// git converts them when the file is added, so that a checkout with CRLF
+// line endings, such as one with core.autocrlf, compares equal to the LF
+// blobs in the commit.
+func (cs *changedSince) readFile(path string, relPath string, entry git.TreeEntry) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return cs.repo.CleanContent(relPath, content, entry.ID)
+}
+
+// changed reports whether path was added, or has different content, since
+// the commit.
+func (cs *changedSince) changed(path string) (bool, string, error) {
+	relPath, entry, ok, err := cs.entry(path)
+	if err != nil {
+		return false, "", err
+	}
+	if !ok {
+		return true, "added", nil
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, "", err
+	}
+	// The blob of a symlink holds its target.
+	var content []byte
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return false, "", err
+		}
+		content = []byte(filepath.ToSlash(target))
+	} else {
+		content, err = cs.readFile(path, relPath, entry)
+		if err != nil {
+			return false, "", err
+		}
+	}
+	if cs.repo.HashBlob(content) != entry.ID {
+		return true, "modified", nil
+	}
+	return false, "unchanged", nil
+}
+
+// ExcludeUnchanged returns the paths that were added or modified since the
+// git revision rev, comparing the working tree to the commit that rev
+// names, and the paths that are left out because they are unchanged.
+func ExcludeUnchanged(rev string, paths []string) ([]string, []ExcludedPath, error) {
+	cs, err := newChangedSince(rev)
+	if err != nil {
+		return nil, nil, err
+	}
+	pathsToFormat := []string{}
+	unchanged := []ExcludedPath{}
+	for _, path := range paths {
+		changed, how, err := cs.changed(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if changed {
+			pathsToFormat = append(pathsToFormat, path)
+		} else {
+			logger.Debug(logger.DebugCodePaths, "%s is %s since %s, excluding", path, how, rev)
+			unchanged = append(unchanged, ExcludedPath{Path: path, Reason: cs.describe(how)})
+		}
+	}
+	logger.Debug(logger.DebugCodePaths, "paths to format: %s", pathsToFormat)
+	return pathsToFormat, unchanged, nil
+}
+
+// ExplainChanged reports whether path was added or modified since the git
+// revision rev, and how.
+func ExplainChanged(rev string, path string) (bool, string, error) {
+	cs, err := newChangedSince(rev)
+	if err != nil {
+		return false, "", err
+	}
+	changed, how, err := cs.changed(path)
+	if err != nil {
+		return false, "", err
+	}
+	return changed, cs.describe(how), nil
+}
+
+// describe says how a file changed since the revision, naming the commit
+// it resolved to.
+func (cs *changedSince) describe(how string) string {
+	return fmt.Sprintf("%s since %s (%s)", how, cs.rev, cs.commit[:min(len(cs.commit), 12)])
+}
+
+// ChangedLineRanges returns the ranges of lines in each of paths that were
+// added or changed since the git revision rev. Files that were added since
+// rev have no entry, since all of their lines are new.
+func ChangedLineRanges(rev string, paths []string) (map[string][]LineRange, error) {
+	cs, err := newChangedSince(rev)
+	if err != nil {
+		return nil, err
+	}
+	lineRanges := map[string][]LineRange{}
+	for _, path := range paths {
+		relPath, entry, ok, err := cs.entry(path)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		_, original, err := cs.repo.ReadObject(entry.ID)
+		if err != nil {
+			return nil, err
+		}
+		content, err := cs.readFile(path, relPath, entry)
+		if err != nil {
+			return nil, err
+		}
+		original, _ = StripBOM(original)
+		content, _ = StripBOM(content)
+		lineRanges[path] = LineRangesFromLines(multilinediff.ChangedLines(string(original), string(content), "\n"))
+		logger.Debug(logger.DebugCodePaths, "changed lines in %s: %v", path, lineRanges[path])
+	}
+	return lineRanges, nil
+}
+
 const DefaultPatternFile = "yamlfmt.patterns"
 
 // PatternFileCollector determines which files to format and which to ignore based on a pattern file in gitignore(5) syntax.
 type PatternFileCollector struct {
+	Symlinks SymlinkPolicy
+
 	fs      fs.FS
 	matcher *ignore.GitIgnore
+	// The directory that fs is, if it is a directory on disk. Symlinks
+	// are only followed there.
+	dir string
 }
 
 // NewPatternFileCollector initializes a new PatternFile using the provided file(s).
@@ -287,7 +895,9 @@ func NewPatternFileCollector(files ...string) (*PatternFileCollector, error) {
 		return nil, fmt.Errorf("os.Getwd: %w", err)
 	}
 
-	return NewPatternFileCollectorFS(r, os.DirFS(wd)), nil
+	collector := NewPatternFileCollectorFS(r, os.DirFS(wd))
+	collector.dir = wd
+	return collector, nil
 }
 
 // cat concatenates the contents of all files in its argument list.
@@ -329,31 +939,323 @@ func NewPatternFileCollectorFS(r io.Reader, fs fs.FS) *PatternFileCollector {
 	}
 }
 
+// ExplainPath implements the PathExplainer interface.
+func (c *PatternFileCollector) ExplainPath(path string) (bool, string, error) {
+	path = filepath.ToSlash(filepath.Clean(path))
+	ok, pattern := c.matcher.MatchesPathHow(path)
+	switch {
+	case pattern == nil:
+		return false, "not matched by any pattern in the pattern file", nil
+	case !ok || pattern.Negate:
+		return false, fmt.Sprintf("negated by pattern %q on line %d of the pattern file", pattern.Line, pattern.LineNo), nil
+	}
+	return true, fmt.Sprintf("matched by pattern %q on line %d of the pattern file", pattern.Line, pattern.LineNo), nil
+}
+
 // CollectPaths implements the PathCollector interface.
 func (c *PatternFileCollector) CollectPaths() ([]string, error) {
 	var files []string
-
-	err := fs.WalkDir(c.fs, ".", func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
+	visit := func(path string, isDir bool) error {
 		ok, pattern := c.matcher.MatchesPathHow(path)
 		switch {
-		case ok && pattern.Negate && d.IsDir():
+		case ok && pattern.Negate && isDir:
 			return fs.SkipDir
 		case ok && pattern.Negate:
 			return nil
-		case ok && d.Type().IsRegular():
+		case ok && !isDir:
 			files = append(files, path)
 		}
-
 		return nil
-	})
+	}
+
+	if c.dir == "" {
+		err := fs.WalkDir(c.fs, ".", func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && !d.Type().IsRegular() {
+				return nil
+			}
+			return visit(path, d.IsDir())
+		})
+		if err != nil {
+			return nil, fmt.Errorf("WalkDir: %w", err)
+		}
+		return files, nil
+	}
 
+	walker, err := newSymlinkWalker(c.Symlinks)
+	if err != nil {
+		return nil, err
+	}
+	err = walker.walkDir(c.dir, func(path string, isDir bool) error {
+		relPath, err := filepath.Rel(c.dir, path)
+		if err != nil {
+			return err
+		}
+		return visit(filepath.ToSlash(relPath), isDir)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("WalkDir: %w", err)
 	}
+	files, _, err = ApplySymlinkPolicy(c.Symlinks, files)
+	return files, err
+}
+
+// GitCollector collects the files that git considers part of the
+// repository that the working directory is in, by reading the index and
+// ignore files from the `.git` directory. Untracked files are collected
+// too if Untracked is set, unless a `.gitignore` file, `info/exclude` or
+// `core.excludesFile` ignores them. Of those files, the ones within an
+// Include path with one of the Extensions, or that are matched by
+// Filenames or SniffContent like with FilepathCollector, are collected,
+// unless they are within an Exclude path or match it as a doublestar
+// pattern.
+type GitCollector struct {
+	Include      []string
+	Exclude      []string
+	Extensions   []string
+	Filenames    []string
+	SniffContent bool
+	// Filters for the files whose content would be sniffed, like with
+	// FilepathCollector.
+	SniffFilters []PathFilter
+	Untracked    bool
+	Symlinks     SymlinkPolicy
+
+	excluded []ExcludedPath
+}
+
+// CollectPaths implements the PathCollector interface.
+func (c *GitCollector) CollectPaths() ([]string, error) {
+	if err := validateFilenames(c.Filenames); err != nil {
+		return nil, err
+	}
+	files, err := c.repoFiles()
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug(logger.DebugCodePaths, "using git path matching. include paths: %s", c.includePaths())
+	pathsToFormat := []string{}
+	sniffCandidates := []string{}
+	c.excluded = []ExcludedPath{}
+	for path := range files {
+		included, err := c.includedBy(path)
+		if err != nil {
+			return nil, err
+		}
+		if included == "" {
+			continue
+		}
+		sniff := false
+		if !ExtensionMatches(path, c.Extensions) && matchFilename(path, c.Filenames) == "" {
+			if !needsSniffing(path, c.SniffContent) {
+				continue
+			}
+			sniff = true
+		}
+		excluded, err := c.excludedBy(path)
+		if err != nil {
+			return nil, err
+		}
+		if sniff {
+			// Excluded files are left out before their content is read.
+			if excluded == "" {
+				sniffCandidates = append(sniffCandidates, path)
+			}
+			continue
+		}
+		if excluded != "" {
+			logger.Debug(logger.DebugCodePaths, "exclude %s matches %s, excluding", excluded, path)
+			c.excluded = append(c.excluded, ExcludedPath{
+				Path:   path,
+				Reason: fmt.Sprintf("matched by exclude %q", excluded),
+			})
+			continue
+		}
+		pathsToFormat = append(pathsToFormat, path)
+	}
+	sniffed, err := sniffPaths(sniffCandidates, c.SniffFilters)
+	if err != nil {
+		return nil, err
+	}
+	pathsToFormat = append(pathsToFormat, sniffed...)
+	pathsToFormat, skipped, err := ApplySymlinkPolicy(c.Symlinks, pathsToFormat)
+	if err != nil {
+		return nil, err
+	}
+	warnSkippedExplicitPaths(c.Include, skipped)
+	c.excluded = append(c.excluded, skipped...)
+	logger.Debug(logger.DebugCodePaths, "paths to format: %s", pathsToFormat)
+	return pathsToFormat, nil
+}
+
+// ExcludedPaths implements the ExcludedPathReporter interface.
+func (c *GitCollector) ExcludedPaths() []ExcludedPath {
+	return c.excluded
+}
+
+// ExplainPath implements the PathExplainer interface.
+func (c *GitCollector) ExplainPath(path string) (bool, string, error) {
+	if err := validateFilenames(c.Filenames); err != nil {
+		return false, "", err
+	}
+	path = filepath.Clean(path)
+	files, err := c.repoFiles()
+	if err != nil {
+		return false, "", err
+	}
+	tracked, ok := files[path]
+	if !ok {
+		reason, err := c.explainNotInRepo(path)
+		return false, reason, err
+	}
+	inRepo := "tracked by git"
+	if !tracked {
+		inRepo = "untracked and not ignored by git"
+	}
+	if !ExtensionMatches(path, c.Extensions) {
+		reason := matchWithoutExtension(path, c.Filenames, c.SniffContent)
+		if reason == "" {
+			return false, fmt.Sprintf("%s, but doesn't have one of the extensions %v", inRepo, c.Extensions), nil
+		}
+		inRepo += ", " + reason
+	}
+	included, err := c.includedBy(path)
+	if err != nil {
+		return false, "", err
+	}
+	if included == "" {
+		return false, fmt.Sprintf("%s, but not in any include path %v", inRepo, c.includePaths()), nil
+	}
+	excluded, err := c.excludedBy(path)
+	if err != nil {
+		return false, "", err
+	}
+	if excluded != "" {
+		return false, fmt.Sprintf("%s, but matched by exclude %q", inRepo, excluded), nil
+	}
+	return true, fmt.Sprintf("%s, in include path %q", inRepo, included), nil
+}
 
-	return files, nil
+func (c *GitCollector) explainNotInRepo(path string) (string, error) {
+	repo, err := git.FindRepo(".")
+	if err != nil {
+		return "", err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	relPath, err := filepath.Rel(repo.WorkTree, absPath)
+	if err != nil {
+		return "", err
+	}
+	ignorer, err := repo.Ignorer()
+	if err != nil {
+		return "", err
+	}
+	// Git doesn't look inside of ignored directories, so the first
+	// ignored directory above the path decides.
+	segments := strings.Split(filepath.ToSlash(relPath), "/")
+	for i := range segments {
+		isDir := i < len(segments)-1
+		pattern, err := ignorer.Match(strings.Join(segments[:i+1], "/"), isDir)
+		if err != nil {
+			return "", err
+		}
+		if pattern != nil && !pattern.Negate {
+			return fmt.Sprintf("ignored by git, matched by pattern %q (%s:%d)", pattern.Line, pattern.Source, pattern.LineNo), nil
+		}
+	}
+	if !c.Untracked {
+		return "not tracked by git, and untracked files aren't collected", nil
+	}
+	return "not in the git repository", nil
+}
+
+// repoFiles returns the files in the repository relative to the working
+// directory, and whether each one is tracked.
+func (c *GitCollector) repoFiles() (map[string]bool, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	repo, err := git.FindRepo(wd)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug(logger.DebugCodePaths, "reading git repository in %s", repo.WorkTree)
+	tracked, err := repo.TrackedFiles()
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]bool{}
+	for _, path := range tracked {
+		files[path] = true
+	}
+	if c.Untracked {
+		untracked, err := repo.UntrackedFiles(tracked)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range untracked {
+			files[path] = false
+		}
+	}
+
+	repoFiles := map[string]bool{}
+	for path, isTracked := range files {
+		absPath := filepath.Join(repo.WorkTree, filepath.FromSlash(path))
+		// Files that were deleted but not staged are still in the index.
+		if _, err := os.Lstat(absPath); err != nil {
+			continue
+		}
+		relPath, err := filepath.Rel(wd, absPath)
+		if err != nil {
+			return nil, err
+		}
+		repoFiles[relPath] = isTracked
+	}
+	return repoFiles, nil
+}
+
+func (c *GitCollector) includePaths() []string {
+	if len(c.Include) == 0 {
+		return []string{"."}
+	}
+	return c.Include
+}
+
+func (c *GitCollector) includedBy(path string) (string, error) {
+	for _, inclPath := range c.includePaths() {
+		within, err := isWithinDir(path, inclPath)
+		if err != nil {
+			return "", err
+		}
+		if within {
+			return inclPath, nil
+		}
+	}
+	return "", nil
+}
+
+func (c *GitCollector) excludedBy(path string) (string, error) {
+	for _, exclPath := range c.Exclude {
+		within, err := isWithinDir(path, exclPath)
+		if err != nil {
+			return "", err
+		}
+		if within {
+			return exclPath, nil
+		}
+		match, err := doublestar.PathMatch(filepath.Clean(exclPath), path)
+		if err != nil {
+			return "", err
+		}
+		if match {
+			return exclPath, nil
+		}
+	}
+	return "", nil
 }
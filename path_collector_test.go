@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -145,6 +146,10 @@ func TestFilepathCollector(t *testing.T) {
 				"a/b/x.yaml": {},
 				"a/b/y.yml":  {},
 			},
+			expectedExcluded: collections.Set[string]{
+				"x.yml":    {},
+				"a/x.yaml": {},
+			},
 		},
 		{
 			name:            "exclude directory",
@@ -177,6 +182,10 @@ func TestFilepathCollector(t *testing.T) {
 				"z.yaml":   {},
 				"a/x.yaml": {},
 			},
+			expectedExcluded: collections.Set[string]{
+				"a/b/x.yaml": {},
+				"a/b/y.yml":  {},
+			},
 		},
 		{
 			name: "don't get files with wrong extension",
@@ -217,6 +226,70 @@ func TestFilepathCollector(t *testing.T) {
 				"y.yaml.gotmpl": {},
 			},
 		},
+		{
+			name: "filenames",
+			files: []tempfile.Path{
+				{FilePath: "x.yaml"},
+				{FilePath: ".clang-format"},
+				{FilePath: "Chart.lock"},
+				{FilePath: "Cargo.lock"},
+			},
+			includePatterns: testPatterns{
+				{pattern: ""}, // with the test this functionally means the whole temp dir
+			},
+			extensions: []string{"yaml"},
+			filenames:  []string{".clang-format", "Chart.*"},
+			expectedFiles: collections.Set[string]{
+				"x.yaml":        {},
+				".clang-format": {},
+				"Chart.lock":    {},
+			},
+		},
+		{
+			name: "sniff content",
+			files: []tempfile.Path{
+				{FilePath: "x.yaml"},
+				{FilePath: "document_start", Content: []byte("---\na: 1\n")},
+				{FilePath: ".config", Content: []byte("# yaml-language-server: $schema=schema.json\na: 1\n")},
+				{FilePath: "modeline", Content: []byte("# vim: set ft=yaml:\na: 1\n")},
+				{FilePath: "script", Content: []byte("#!/bin/sh\necho ---\n")},
+				{FilePath: "notes.txt", Content: []byte("---\na: 1\n")},
+			},
+			includePatterns: testPatterns{
+				{pattern: ""}, // with the test this functionally means the whole temp dir
+			},
+			extensions:   []string{"yaml"},
+			sniffContent: true,
+			expectedFiles: collections.Set[string]{
+				"x.yaml":         {},
+				"document_start": {},
+				".config":        {},
+				"modeline":       {},
+			},
+		},
+		{
+			name: "sniff content skips git and excluded directories",
+			files: []tempfile.Path{
+				{FilePath: "document_start", Content: []byte("---\na: 1\n")},
+				{FilePath: ".git", IsDir: true},
+				{FilePath: ".git/description", Content: []byte("---\na: 1\n")},
+				{FilePath: ".git/x.yaml"},
+				{FilePath: "vendor", IsDir: true},
+				{FilePath: "vendor/document_start", Content: []byte("---\na: 1\n")},
+			},
+			includePatterns: testPatterns{
+				{pattern: ""}, // with the test this functionally means the whole temp dir
+			},
+			excludePatterns: testPatterns{
+				{pattern: "vendor"},
+			},
+			extensions:   []string{"yaml"},
+			sniffContent: true,
+			expectedFiles: collections.Set[string]{
+				"document_start": {},
+			},
+			expectedExcluded: collections.Set[string]{},
+		},
 	}.runAll(t, useFilepathCollector)
 }
 
@@ -283,6 +356,11 @@ func TestDoublestarCollectorExcludeDirectory(t *testing.T) {
 				"x.yaml":   {},
 				"y/y.yaml": {},
 			},
+			expectedExcluded: collections.Set[string]{
+				"z/z.yaml":  {},
+				"z/z1.yaml": {},
+				"z/z2.yaml": {},
+			},
 		},
 		{
 			name:  "exclude_directory/absolute include and exclude",
@@ -367,8 +445,12 @@ type testCase struct {
 	files           []tempfile.Path
 	includePatterns testPatterns
 	extensions      []string
+	filenames       []string
+	sniffContent    bool
 	excludePatterns testPatterns
 	expectedFiles   collections.Set[string]
+	// When set, the paths that the collector reports as excluded.
+	expectedExcluded collections.Set[string]
 }
 
 func (tc testCase) run(t *testing.T, makeCollector makeCollectorFunc) {
@@ -413,6 +495,31 @@ func (tc testCase) run(t *testing.T, makeCollector makeCollectorFunc) {
 		if !filesToFormat.Equals(tc.expectedFiles) {
 			t.Fatalf("Expected to receive paths %v\nbut got %v", tc.expectedFiles, filesToFormat)
 		}
+
+		if tc.expectedExcluded == nil {
+			return
+		}
+		reporter, ok := collector.(yamlfmt.ExcludedPathReporter)
+		if !ok {
+			t.Fatalf("Expected the collector to report excluded paths")
+		}
+		excluded := collections.Set[string]{}
+		for _, excludedPath := range reporter.ExcludedPaths() {
+			if excludedPath.Reason == "" {
+				t.Fatalf("Expected a reason for excluding %s", excludedPath.Path)
+			}
+			excludedRelPath := excludedPath.Path
+			if strings.HasPrefix(excludedRelPath, "/") {
+				excludedRelPath, err = filepath.Rel(tempPath, excludedPath.Path)
+				if err != nil {
+					t.Fatalf("Path %s could not match to path %s", tempPath, excludedPath.Path)
+				}
+			}
+			excluded.Add(excludedRelPath)
+		}
+		if !excluded.Equals(tc.expectedExcluded) {
+			t.Fatalf("Expected excluded paths %v\nbut got %v", tc.expectedExcluded, excluded)
+		}
 	})
 
 	// Restore the starting directory if we changed in the test.
@@ -433,9 +540,11 @@ type makeCollectorFunc func(tc testCase, path string) yamlfmt.PathCollector
 
 func useFilepathCollector(tc testCase, path string) yamlfmt.PathCollector {
 	return &yamlfmt.FilepathCollector{
-		Include:    tc.includePatterns.allPatterns(path),
-		Exclude:    tc.excludePatterns.allPatterns(path),
-		Extensions: tc.extensions,
+		Include:      tc.includePatterns.allPatterns(path),
+		Exclude:      tc.excludePatterns.allPatterns(path),
+		Extensions:   tc.extensions,
+		Filenames:    tc.filenames,
+		SniffContent: tc.sniffContent,
 	}
 }
 
@@ -538,3 +647,444 @@ func TestPatternFile(t *testing.T) {
 		})
 	}
 }
+
+func TestGitCollector(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	testStartDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("could not get working directory: %v", err)
+	}
+	tempPath := t.TempDir()
+	files := tempfile.Paths{
+		{BasePath: tempPath, FilePath: ".gitignore", Content: []byte("build/\n")},
+		{BasePath: tempPath, FilePath: "tracked.yaml"},
+		{BasePath: tempPath, FilePath: "untracked.yml"},
+		{BasePath: tempPath, FilePath: "README.md"},
+		{BasePath: tempPath, FilePath: "build", IsDir: true},
+		{BasePath: tempPath, FilePath: "build/ignored.yaml"},
+		{BasePath: tempPath, FilePath: "charts", IsDir: true},
+		{BasePath: tempPath, FilePath: "charts/.gitignore", Content: []byte("local.yaml\n")},
+		{BasePath: tempPath, FilePath: "charts/values.yaml"},
+		{BasePath: tempPath, FilePath: "charts/local.yaml"},
+		{BasePath: tempPath, FilePath: "charts/gen", IsDir: true},
+		{BasePath: tempPath, FilePath: "charts/gen/values.yaml"},
+	}
+	if err := files.CreateAll(); err != nil {
+		t.Fatalf("could not create test files: %v", err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", ".gitignore", "tracked.yaml", "README.md", "charts/values.yaml", "charts/gen/values.yaml"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tempPath
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	os.Chdir(tempPath)
+	defer os.Chdir(testStartDir)
+
+	testCases := []struct {
+		name      string
+		untracked bool
+		expected  collections.Set[string]
+	}{
+		{
+			name:      "tracked files",
+			untracked: false,
+			expected:  collections.Set[string]{"tracked.yaml": {}, "charts/values.yaml": {}},
+		},
+		{
+			name:      "untracked files",
+			untracked: true,
+			expected:  collections.Set[string]{"tracked.yaml": {}, "untracked.yml": {}, "charts/values.yaml": {}},
+		},
+	}
+	for _, tc := range testCases {
+		collector := &yamlfmt.GitCollector{
+			Include:    []string{"."},
+			Exclude:    []string{"charts/gen"},
+			Extensions: []string{"yaml", "yml"},
+			Untracked:  tc.untracked,
+		}
+		paths, err := collector.CollectPaths()
+		if err != nil {
+			t.Fatalf("%s: CollectPaths failed: %v", tc.name, err)
+		}
+		if !collections.SliceToSet(paths).Equals(tc.expected) {
+			t.Fatalf("%s: expected paths %v\nbut got %v", tc.name, tc.expected, paths)
+		}
+	}
+
+	collector := &yamlfmt.GitCollector{Extensions: []string{"yaml"}, Untracked: true}
+	collected, reason, err := collector.ExplainPath("charts/local.yaml")
+	if err != nil {
+		t.Fatalf("ExplainPath failed: %v", err)
+	}
+	if collected || !strings.Contains(reason, `matched by pattern "local.yaml"`) {
+		t.Fatalf("expected charts/local.yaml to be ignored by charts/.gitignore, got: %s", reason)
+	}
+}
+
+func TestExcludeUnchanged(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	testStartDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("could not get working directory: %v", err)
+	}
+	tempPath := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = tempPath
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	write := func(path string, content string) {
+		if err := os.WriteFile(filepath.Join(tempPath, path), []byte(content), 0644); err != nil {
+			t.Fatalf("could not write %s: %v", path, err)
+		}
+	}
+	git("init", "-q")
+	write("a.yaml", "a: 1\n")
+	write("b.yaml", "b: 1\n")
+	write("c.yaml", "c: 1\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "first")
+	git("tag", "-a", "-m", "base", "base")
+	write("a.yaml", "a: 2\n")
+	git("commit", "-q", "-a", "-m", "second")
+	// Pack the objects so that they're read from a pack file.
+	git("gc", "-q")
+	write("b.yaml", "b: 2\n")
+	write("d.yaml", "d: 1\n")
+	os.Chdir(tempPath)
+	defer os.Chdir(testStartDir)
+
+	paths := []string{"a.yaml", "b.yaml", "c.yaml", "d.yaml"}
+	testCases := []struct {
+		rev      string
+		expected collections.Set[string]
+	}{
+		{rev: "HEAD", expected: collections.Set[string]{"b.yaml": {}, "d.yaml": {}}},
+		{rev: "base", expected: collections.Set[string]{"a.yaml": {}, "b.yaml": {}, "d.yaml": {}}},
+		{rev: "HEAD~1", expected: collections.Set[string]{"a.yaml": {}, "b.yaml": {}, "d.yaml": {}}},
+	}
+	for _, tc := range testCases {
+		changed, unchanged, err := yamlfmt.ExcludeUnchanged(tc.rev, paths)
+		if err != nil {
+			t.Fatalf("%s: ExcludeUnchanged failed: %v", tc.rev, err)
+		}
+		if !collections.SliceToSet(changed).Equals(tc.expected) {
+			t.Fatalf("%s: expected paths %v\nbut got %v", tc.rev, tc.expected, changed)
+		}
+		if len(changed)+len(unchanged) != len(paths) {
+			t.Fatalf("%s: expected the other paths to be reported as unchanged, got %v", tc.rev, unchanged)
+		}
+		for _, excluded := range unchanged {
+			if tc.expected.Contains(excluded.Path) || !strings.HasPrefix(excluded.Reason, "unchanged since "+tc.rev+" (") {
+				t.Fatalf("%s: unexpected unchanged path %s: %s", tc.rev, excluded.Path, excluded.Reason)
+			}
+		}
+	}
+
+	if _, _, err := yamlfmt.ExcludeUnchanged("missing", paths); err == nil {
+		t.Fatal("expected an error for a revision that doesn't exist")
+	}
+
+	write("c.yaml", "c: 1\nc2: 2\n")
+	lineRanges, err := yamlfmt.ChangedLineRanges("HEAD", paths)
+	if err != nil {
+		t.Fatalf("ChangedLineRanges failed: %v", err)
+	}
+	expectedRanges := map[string][]yamlfmt.LineRange{
+		"a.yaml": {},
+		"b.yaml": {{Start: 1, End: 1}},
+		"c.yaml": {{Start: 2, End: 2}},
+	}
+	if diff := cmp.Diff(expectedRanges, lineRanges); diff != "" {
+		t.Fatalf("unexpected line ranges (-want +got):\n%s", diff)
+	}
+}
+
+func TestExcludeUnchangedLineEndings(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	testStartDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("could not get working directory: %v", err)
+	}
+	tempPath := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = tempPath
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	write := func(path string, content string) {
+		if err := os.WriteFile(filepath.Join(tempPath, path), []byte(content), 0644); err != nil {
+			t.Fatalf("could not write %s: %v", path, err)
+		}
+	}
+	git("init", "-q")
+	git("config", "core.autocrlf", "false")
+	write(".gitattributes", "binary.yaml -text\neol.yaml eol=crlf\n")
+	write("autocrlf.yaml", "a: 1\n")
+	write("modified.yaml", "a: 1\n")
+	write("binary.yaml", "a: 1\n")
+	write("eol.yaml", "a: 1\n")
+	write("committed_crlf.yaml", "a: 1\r\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "first")
+	// Check out the files the way git does on Windows.
+	git("config", "core.autocrlf", "true")
+	write("autocrlf.yaml", "a: 1\r\n")
+	write("modified.yaml", "a: 2\r\n")
+	write("binary.yaml", "a: 1\r\n")
+	write("eol.yaml", "a: 1\r\n")
+	os.Chdir(tempPath)
+	defer os.Chdir(testStartDir)
+
+	paths := []string{"autocrlf.yaml", "modified.yaml", "binary.yaml", "eol.yaml", "committed_crlf.yaml"}
+	changed, _, err := yamlfmt.ExcludeUnchanged("HEAD", paths)
+	if err != nil {
+		t.Fatalf("ExcludeUnchanged failed: %v", err)
+	}
+	expected := collections.Set[string]{"modified.yaml": {}, "binary.yaml": {}}
+	if !collections.SliceToSet(changed).Equals(expected) {
+		t.Fatalf("expected paths %v\nbut got %v", expected, changed)
+	}
+
+	write("autocrlf.yaml", "a: 1\r\nb: 2\r\n")
+	lineRanges, err := yamlfmt.ChangedLineRanges("HEAD", []string{"autocrlf.yaml", "eol.yaml"})
+	if err != nil {
+		t.Fatalf("ChangedLineRanges failed: %v", err)
+	}
+	expectedRanges := map[string][]yamlfmt.LineRange{
+		"autocrlf.yaml": {{Start: 2, End: 2}},
+		"eol.yaml":      {},
+	}
+	if diff := cmp.Diff(expectedRanges, lineRanges); diff != "" {
+		t.Fatalf("unexpected line ranges (-want +got):\n%s", diff)
+	}
+}
+
+func TestExcludeWithIgnoreFiles(t *testing.T) {
+	testStartDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("could not get working directory: %v", err)
+	}
+	tempPath := t.TempDir()
+	files := tempfile.Paths{
+		{BasePath: tempPath, FilePath: ".gitignore", Content: []byte("build/\n")},
+		{BasePath: tempPath, FilePath: ".yamlfmtignore", Content: []byte("generated.yaml\n")},
+		{BasePath: tempPath, FilePath: "x.yaml"},
+		{BasePath: tempPath, FilePath: "generated.yaml"},
+		{BasePath: tempPath, FilePath: "build", IsDir: true},
+		{BasePath: tempPath, FilePath: "build/x.yaml"},
+		{BasePath: tempPath, FilePath: "charts", IsDir: true},
+		{BasePath: tempPath, FilePath: "charts/.gitignore", Content: []byte("local.yaml\n")},
+		{BasePath: tempPath, FilePath: "charts/.yamlfmtignore", Content: []byte("!generated.yaml\n")},
+		{BasePath: tempPath, FilePath: "charts/local.yaml"},
+		{BasePath: tempPath, FilePath: "charts/generated.yaml"},
+		{BasePath: tempPath, FilePath: "charts/values.yaml"},
+	}
+	if err := files.CreateAll(); err != nil {
+		t.Fatalf("could not create test files: %v", err)
+	}
+	os.Chdir(tempPath)
+	defer os.Chdir(testStartDir)
+
+	paths := []string{"x.yaml", "generated.yaml", "build/x.yaml", "charts/local.yaml", "charts/generated.yaml", "charts/values.yaml"}
+	gitignored, err := yamlfmt.ExcludeWithGitignore(".gitignore", paths)
+	if err != nil {
+		t.Fatalf("ExcludeWithGitignore failed: %v", err)
+	}
+	expected := collections.Set[string]{"x.yaml": {}, "generated.yaml": {}, "charts/generated.yaml": {}, "charts/values.yaml": {}}
+	if !collections.SliceToSet(gitignored).Equals(expected) {
+		t.Fatalf("expected paths %v\nbut got %v", expected, gitignored)
+	}
+
+	yamlfmtIgnored, err := yamlfmt.ExcludeWithYamlfmtIgnore(paths)
+	if err != nil {
+		t.Fatalf("ExcludeWithYamlfmtIgnore failed: %v", err)
+	}
+	expected = collections.Set[string]{"x.yaml": {}, "build/x.yaml": {}, "charts/local.yaml": {}, "charts/generated.yaml": {}, "charts/values.yaml": {}}
+	if !collections.SliceToSet(yamlfmtIgnored).Equals(expected) {
+		t.Fatalf("expected paths %v\nbut got %v", expected, yamlfmtIgnored)
+	}
+}
+
+func TestSniffFilters(t *testing.T) {
+	testStartDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("could not get working directory: %v", err)
+	}
+	tempPath := t.TempDir()
+	files := tempfile.Paths{
+		{BasePath: tempPath, FilePath: ".yamlfmtignore", Content: []byte("node_modules/\n")},
+		{BasePath: tempPath, FilePath: "x.yaml"},
+		{BasePath: tempPath, FilePath: "config", Content: []byte("---\na: 1\n")},
+		{BasePath: tempPath, FilePath: "vendor", IsDir: true},
+		{BasePath: tempPath, FilePath: "vendor/config", Content: []byte("---\na: 1\n")},
+		{BasePath: tempPath, FilePath: "node_modules", IsDir: true},
+		{BasePath: tempPath, FilePath: "node_modules/config", Content: []byte("---\na: 1\n")},
+	}
+	if err := files.CreateAll(); err != nil {
+		t.Fatalf("could not create test files: %v", err)
+	}
+	os.Chdir(tempPath)
+	defer os.Chdir(testStartDir)
+
+	filtered := []string{}
+	collector := &yamlfmt.FilepathCollector{
+		Include:      []string{"."},
+		Exclude:      []string{"vendor"},
+		Extensions:   []string{"yaml"},
+		SniffContent: true,
+		SniffFilters: []yamlfmt.PathFilter{func(paths []string) ([]string, error) {
+			filtered = append(filtered, paths...)
+			return yamlfmt.ExcludeWithYamlfmtIgnore(paths)
+		}},
+	}
+	paths, err := collector.CollectPaths()
+	if err != nil {
+		t.Fatalf("CollectPaths failed: %v", err)
+	}
+	expected := collections.Set[string]{"x.yaml": {}, "config": {}}
+	if !collections.SliceToSet(paths).Equals(expected) {
+		t.Fatalf("expected paths %v\nbut got %v", expected, paths)
+	}
+	// Only the files that aren't excluded and don't match by extension
+	// go through the filters.
+	expected = collections.Set[string]{".yamlfmtignore": {}, "config": {}, "node_modules/config": {}}
+	if !collections.SliceToSet(filtered).Equals(expected) {
+		t.Fatalf("expected the filters to get %v\nbut got %v", expected, filtered)
+	}
+}
+
+func TestSymlinkPolicy(t *testing.T) {
+	testStartDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("could not get working directory: %v", err)
+	}
+	tempPath := t.TempDir()
+	rootPath := filepath.Join(tempPath, "root")
+	files := tempfile.Paths{
+		{BasePath: tempPath, FilePath: "outside", IsDir: true},
+		{BasePath: tempPath, FilePath: "outside/o.yaml"},
+		{BasePath: tempPath, FilePath: "root", IsDir: true},
+		{BasePath: rootPath, FilePath: "vendor", IsDir: true},
+		{BasePath: rootPath, FilePath: "vendor/x.yaml"},
+	}
+	if err := files.CreateAll(); err != nil {
+		t.Fatalf("could not create test files: %v", err)
+	}
+	links := map[string]string{
+		"linked":          "vendor",
+		"direct.yaml":     "vendor/x.yaml",
+		"out":             "../outside",
+		"vendor/loop":     "..",
+		"broken.yaml":     "nowhere.yaml",
+		"out_direct.yaml": "../outside/o.yaml",
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(rootPath, link)); err != nil {
+			t.Skipf("could not create symlink: %v", err)
+		}
+	}
+	os.Chdir(rootPath)
+	defer os.Chdir(testStartDir)
+
+	testCases := []struct {
+		policy   yamlfmt.SymlinkPolicy
+		expected collections.Set[string]
+	}{
+		{
+			policy:   yamlfmt.SymlinkPolicySkip,
+			expected: collections.Set[string]{"vendor/x.yaml": {}},
+		},
+		{
+			policy:   yamlfmt.SymlinkPolicyFollowWithinRoot,
+			expected: collections.Set[string]{"vendor/x.yaml": {}},
+		},
+		{
+			policy:   yamlfmt.SymlinkPolicyFollow,
+			expected: collections.Set[string]{"vendor/x.yaml": {}, "out/o.yaml": {}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			collectors := map[string]yamlfmt.PathCollector{
+				"filepath": &yamlfmt.FilepathCollector{
+					Include:    []string{"."},
+					Extensions: []string{"yaml"},
+					Symlinks:   tc.policy,
+				},
+				"doublestar": &yamlfmt.DoublestarCollector{
+					Include:  []string{"**/*.yaml"},
+					Symlinks: tc.policy,
+				},
+			}
+			for name, collector := range collectors {
+				paths, err := collector.CollectPaths()
+				if err != nil {
+					t.Fatalf("%s collector failed: %v", name, err)
+				}
+				if !collections.SliceToSet(paths).Equals(tc.expected) {
+					t.Fatalf("%s collector: expected paths %v\nbut got %v", name, tc.expected, paths)
+				}
+			}
+		})
+	}
+
+	t.Run("explicit path outside of the root", func(t *testing.T) {
+		collector := &yamlfmt.FilepathCollector{
+			Include:    []string{"out_direct.yaml", "vendor/x.yaml"},
+			Extensions: []string{"yaml"},
+			Symlinks:   yamlfmt.SymlinkPolicyFollowWithinRoot,
+		}
+		paths, err := collector.CollectPaths()
+		if err != nil {
+			t.Fatalf("CollectPaths failed: %v", err)
+		}
+		if diff := cmp.Diff([]string{"vendor/x.yaml"}, paths); diff != "" {
+			t.Fatalf("unexpected paths (-want +got):\n%s", diff)
+		}
+		excluded := collector.ExcludedPaths()
+		if len(excluded) != 1 || excluded[0].Path != "out_direct.yaml" || !strings.Contains(excluded[0].Reason, "outside of") {
+			t.Fatalf("expected out_direct.yaml to be excluded for leading outside of the root, got %v", excluded)
+		}
+	})
+
+	t.Run("unsupported policy", func(t *testing.T) {
+		_, _, err := yamlfmt.ApplySymlinkPolicy("sometimes", []string{"vendor/x.yaml"})
+		if err == nil {
+			t.Fatal("expected an error for an unsupported policy")
+		}
+	})
+
+	t.Run("dedups by real path", func(t *testing.T) {
+		paths, _, err := yamlfmt.ApplySymlinkPolicy(yamlfmt.SymlinkPolicyFollow, []string{"direct.yaml", "linked/x.yaml", "vendor/x.yaml", "out_direct.yaml"})
+		if err != nil {
+			t.Fatalf("ApplySymlinkPolicy failed: %v", err)
+		}
+		expected := []string{"vendor/x.yaml", "out_direct.yaml"}
+		if diff := cmp.Diff(expected, paths); diff != "" {
+			t.Fatalf("unexpected paths (-want +got):\n%s", diff)
+		}
+	})
+}
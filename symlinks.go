@@ -0,0 +1,269 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yamlfmt
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/yamlfmt/internal/collections"
+	"github.com/google/yamlfmt/internal/logger"
+)
+
+// SymlinkPolicy controls how path collection treats symlinks.
+type SymlinkPolicy string
+
+const (
+	// SymlinkPolicySkip leaves out symlinks to files and doesn't walk
+	// symlinks to directories.
+	SymlinkPolicySkip SymlinkPolicy = "skip"
+	// SymlinkPolicyFollow follows every symlink.
+	SymlinkPolicyFollow SymlinkPolicy = "follow"
+	// SymlinkPolicyFollowWithinRoot follows symlinks that point within
+	// the working directory, and skips the rest.
+	SymlinkPolicyFollowWithinRoot SymlinkPolicy = "follow_within_root"
+)
+
+type UnsupportedSymlinkPolicyError struct {
+	policy SymlinkPolicy
+}
+
+func (e UnsupportedSymlinkPolicyError) Error() string {
+	return fmt.Sprintf("unsupported symlinks policy %s, supported policies are skip, follow, and follow_within_root", e.policy)
+}
+
+// resolve returns the policy to use, where an empty policy is
+// SymlinkPolicyFollowWithinRoot.
+func (p SymlinkPolicy) resolve() (SymlinkPolicy, error) {
+	switch p {
+	case "":
+		return SymlinkPolicyFollowWithinRoot, nil
+	case SymlinkPolicySkip, SymlinkPolicyFollow, SymlinkPolicyFollowWithinRoot:
+		return p, nil
+	}
+	return "", UnsupportedSymlinkPolicyError{policy: p}
+}
+
+// symlinkWalker walks directories, treating symlinks according to a
+// policy.
+type symlinkWalker struct {
+	policy SymlinkPolicy
+	// The resolved path of the working directory.
+	root string
+	errs []error
+}
+
+func newSymlinkWalker(policy SymlinkPolicy) (*symlinkWalker, error) {
+	policy, err := policy.resolve()
+	if err != nil {
+		return nil, err
+	}
+	root, err := realWorkingDir()
+	if err != nil {
+		return nil, err
+	}
+	return &symlinkWalker{policy: policy, root: root}, nil
+}
+
+func realWorkingDir() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(wd)
+}
+
+// walkDir calls fn for each file and directory below dir, with paths
+// joined to dir. A directory that fn returns fs.SkipDir for isn't walked.
+// A symlinked directory that leads back to one that is being walked is
+// skipped, so that symlink loops end. Errors reading directories are
+// collected and returned together once the walk is done.
+func (w *symlinkWalker) walkDir(dir string, fn func(path string, isDir bool) error) error {
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	w.errs = nil
+	if err := w.walk(dir, []string{realDir}, fn); err != nil {
+		return err
+	}
+	return errors.Join(w.errs...)
+}
+
+func (w *symlinkWalker) walk(dir string, ancestors []string, fn func(path string, isDir bool) error) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		w.errs = append(w.errs, fmt.Errorf("error for path %s: %v", dir, err))
+		return nil
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		isDir := entry.IsDir()
+		realPath := filepath.Join(ancestors[len(ancestors)-1], entry.Name())
+		if entry.Type()&fs.ModeSymlink != 0 {
+			target, ok := w.followSymlink(path)
+			if !ok {
+				continue
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			isDir = info.IsDir()
+			realPath = target
+		}
+		if !isDir {
+			if err := fn(path, false); err != nil {
+				return err
+			}
+			continue
+		}
+		if slices.Contains(ancestors, realPath) {
+			logger.Debug(logger.DebugCodePaths, "symlink loop at %s, skipping", path)
+			continue
+		}
+		if err := fn(path, true); err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				continue
+			}
+			return err
+		}
+		if err := w.walk(path, append(ancestors, realPath), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// followSymlink returns where the symlink at path leads, and whether the
+// policy follows it.
+func (w *symlinkWalker) followSymlink(path string) (string, bool) {
+	if w.policy == SymlinkPolicySkip {
+		logger.Debug(logger.DebugCodePaths, "skipping symlink %s", path)
+		return "", false
+	}
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		logger.Debug(logger.DebugCodePaths, "skipping broken symlink %s", path)
+		return "", false
+	}
+	if w.policy == SymlinkPolicyFollowWithinRoot && !isWithinRealDir(target, w.root) {
+		logger.Debug(logger.DebugCodePaths, "skipping symlink %s to %s, outside of %s", path, target, w.root)
+		return "", false
+	}
+	return target, true
+}
+
+func isWithinRealDir(path string, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// ApplySymlinkPolicy removes the paths that the policy doesn't allow,
+// whether they are symlinks themselves or are in a symlinked directory,
+// and keeps only one path for each file they resolve to. Where paths
+// resolve to the same file, the one without symlinks is kept, or else the
+// first one in sorted order. The paths that the policy doesn't allow are
+// returned with the reason for each one.
+func ApplySymlinkPolicy(policy SymlinkPolicy, paths []string) ([]string, []ExcludedPath, error) {
+	w, err := newSymlinkWalker(policy)
+	if err != nil {
+		return nil, nil, err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, nil, err
+	}
+	sortedPaths := slices.Clone(paths)
+	sort.Strings(sortedPaths)
+
+	kept := map[string]string{}
+	realPaths := []string{}
+	skipped := []ExcludedPath{}
+	skip := func(path string, reason string) {
+		logger.Debug(logger.DebugCodePaths, "%s %s, skipping", path, reason)
+		skipped = append(skipped, ExcludedPath{Path: path, Reason: reason})
+	}
+	for _, path := range sortedPaths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		realPath, err := filepath.EvalSymlinks(absPath)
+		if err != nil {
+			skip(path, fmt.Sprintf("can't be resolved (%v)", err))
+			continue
+		}
+		// The path the file would have if no symlinks led to it. Outside
+		// of the working directory, only the file itself is checked.
+		var unlinkedPath string
+		if rel, err := filepath.Rel(wd, absPath); err == nil && isWithinRealDir(absPath, wd) {
+			unlinkedPath = filepath.Join(w.root, rel)
+		} else if realDir, err := filepath.EvalSymlinks(filepath.Dir(absPath)); err == nil {
+			unlinkedPath = filepath.Join(realDir, filepath.Base(absPath))
+		}
+		throughSymlink := realPath != unlinkedPath
+		if throughSymlink {
+			switch w.policy {
+			case SymlinkPolicySkip:
+				skip(path, fmt.Sprintf("is or is in a symlink, with symlinks: %s", w.policy))
+				continue
+			case SymlinkPolicyFollowWithinRoot:
+				if !isWithinRealDir(realPath, w.root) {
+					skip(path, fmt.Sprintf("leads to %s, outside of %s, with symlinks: %s", realPath, w.root, w.policy))
+					continue
+				}
+			}
+		}
+		keptPath, seen := kept[realPath]
+		if !seen {
+			realPaths = append(realPaths, realPath)
+			kept[realPath] = path
+			continue
+		}
+		logger.Debug(logger.DebugCodePaths, "%s and %s are the same file", keptPath, path)
+		if !throughSymlink {
+			kept[realPath] = path
+		}
+	}
+
+	result := []string{}
+	for _, realPath := range realPaths {
+		result = append(result, kept[realPath])
+	}
+	return result, skipped, nil
+}
+
+// warnSkippedExplicitPaths warns about the paths that were named
+// explicitly, such as a file passed on the command line, but that the
+// symlink policy left out, since they would otherwise vanish without a
+// word.
+func warnSkippedExplicitPaths(explicit []string, skipped []ExcludedPath) {
+	explicitSet := collections.Set[string]{}
+	for _, path := range explicit {
+		explicitSet.Add(filepath.Clean(path))
+	}
+	for _, skippedPath := range skipped {
+		if explicitSet.Contains(filepath.Clean(skippedPath.Path)) {
+			fmt.Fprintf(os.Stderr, "warning: %s won't be formatted, it %s\n", skippedPath.Path, skippedPath.Reason)
+		}
+	}
+}
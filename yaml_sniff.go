@@ -0,0 +1,170 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yamlfmt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/google/yamlfmt/internal/logger"
+)
+
+// The number of bytes at the start of a file that SniffYaml looks at.
+const sniffSize = 1024
+
+var (
+	yamlLanguageServerRegex = regexp.MustCompile(`^#\s*yaml-language-server:`)
+	vimModelineRegex        = regexp.MustCompile(`^#.*\b(?:vi|vim|ex):.*\b(?:ft|filetype|syntax)=yaml\b`)
+	emacsModelineRegex      = regexp.MustCompile(`^#.*-\*-(?:\s*yaml\s*|.*\bmode:\s*yaml\b.*)-\*-`)
+)
+
+// SniffYaml reports whether content looks like the start of a YAML file,
+// and why. It does if a comment before any content is a
+// `yaml-language-server` comment or an editor modeline that sets the file
+// type to yaml, or if the content starts with a `%YAML` directive or a
+// `---` document start. Content with a NUL byte is never YAML.
+func SniffYaml(content []byte) (bool, string) {
+	content = content[:min(len(content), sniffSize)]
+	if bytes.IndexByte(content, 0) >= 0 {
+		return false, ""
+	}
+	content, _ = StripBOM(content)
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case yamlLanguageServerRegex.MatchString(line):
+			return true, fmt.Sprintf("yaml-language-server comment on line %d", i+1)
+		case vimModelineRegex.MatchString(line) || emacsModelineRegex.MatchString(line):
+			return true, fmt.Sprintf("yaml modeline on line %d", i+1)
+		case strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "%YAML"):
+			return true, fmt.Sprintf("%%YAML directive on line %d", i+1)
+		case isDocumentMarker([]byte(line), "---"):
+			return true, fmt.Sprintf("document start on line %d", i+1)
+		}
+		return false, ""
+	}
+	return false, ""
+}
+
+// hasExtension reports whether the file name has an extension, not
+// counting the leading dot of a dotfile.
+func hasExtension(name string) bool {
+	return strings.Contains(strings.TrimPrefix(name, "."), ".")
+}
+
+func validateFilenames(filenames []string) error {
+	for _, pattern := range filenames {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid filenames pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchWithoutExtension describes why a file that doesn't have one of the
+// collected extensions is still collected as YAML, or returns an empty
+// string if it isn't. Its base name is matched against the filenames
+// patterns, and if sniffContent is set, the start of a file without an
+// extension is checked with SniffYaml. The patterns must have been
+// checked with validateFilenames.
+func matchWithoutExtension(filePath string, filenames []string, sniffContent bool) string {
+	if reason := matchFilename(filePath, filenames); reason != "" {
+		return reason
+	}
+	if !needsSniffing(filePath, sniffContent) {
+		return ""
+	}
+	return sniffFile(filePath)
+}
+
+// matchFilename describes which of the filenames patterns the base name of
+// the file matches, or returns an empty string if none do.
+func matchFilename(filePath string, filenames []string) string {
+	name := filepath.Base(filePath)
+	for _, pattern := range filenames {
+		if matched, _ := path.Match(pattern, name); matched {
+			return fmt.Sprintf("matched by filename %q", pattern)
+		}
+	}
+	return ""
+}
+
+// needsSniffing reports whether the content of a file that isn't matched
+// by its extension or name decides if it is collected.
+func needsSniffing(filePath string, sniffContent bool) bool {
+	return sniffContent && !hasExtension(filepath.Base(filePath))
+}
+
+// sniffFile describes why the start of the file looks like YAML, or
+// returns an empty string if it doesn't or can't be read.
+func sniffFile(filePath string) string {
+	content, err := readStart(filePath, sniffSize)
+	if err != nil {
+		logger.Debug(logger.DebugCodePaths, "could not read %s to sniff its content: %v", filePath, err)
+		return ""
+	}
+	if ok, reason := SniffYaml(content); ok {
+		return "content looks like YAML: " + reason
+	}
+	return ""
+}
+
+// sniffPaths returns the paths that are left after the filters, which
+// are run first so that the files they remove are never read, and whose
+// content looks like YAML.
+func sniffPaths(paths []string, filters []PathFilter) ([]string, error) {
+	var err error
+	for _, filter := range filters {
+		if len(paths) == 0 {
+			break
+		}
+		paths, err = filter(paths)
+		if err != nil {
+			return nil, err
+		}
+	}
+	yamlPaths := []string{}
+	for _, path := range paths {
+		if reason := sniffFile(path); reason != "" {
+			logger.Debug(logger.DebugCodePaths, "collecting %s, %s", path, reason)
+			yamlPaths = append(yamlPaths, path)
+		}
+	}
+	return yamlPaths, nil
+}
+
+func readStart(path string, n int) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	content := make([]byte, n)
+	read, err := io.ReadFull(file, content)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	return content[:read], nil
+}
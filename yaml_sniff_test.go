@@ -0,0 +1,55 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yamlfmt_test
+
+import (
+	"testing"
+
+	"github.com/google/yamlfmt"
+)
+
+func TestSniffYaml(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected bool
+	}{
+		{name: "document start", content: "---\na: 1\n", expected: true},
+		{name: "document start after comments", content: "# a comment\n\n--- # doc\na: 1\n", expected: true},
+		{name: "yaml directive", content: "%YAML 1.2\n---\na: 1\n", expected: true},
+		{name: "yaml-language-server", content: "# yaml-language-server: $schema=x.json\na: 1\n", expected: true},
+		{name: "vim modeline", content: "#!/usr/bin/env tool\n# vim: set ft=yaml ts=2:\na: 1\n", expected: true},
+		{name: "vim modeline for another type", content: "# vim: set ft=yamlx:\na: 1\n", expected: false},
+		{name: "emacs modeline", content: "# -*- mode: yaml; -*-\na: 1\n", expected: true},
+		{name: "emacs short modeline", content: "# -*- yaml -*-\na: 1\n", expected: true},
+		{name: "byte order mark", content: "\xef\xbb\xbf---\na: 1\n", expected: true},
+		{name: "content before document start", content: "a: 1\n---\nb: 2\n", expected: false},
+		{name: "marker needs a separator", content: "----\n", expected: false},
+		{name: "shell script", content: "#!/bin/sh\necho ---\n", expected: false},
+		{name: "binary", content: "---\x00\x01", expected: false},
+		{name: "empty", content: "", expected: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			isYaml, reason := yamlfmt.SniffYaml([]byte(tc.content))
+			if isYaml != tc.expected {
+				t.Fatalf("expected %v, got %v (%s)", tc.expected, isYaml, reason)
+			}
+			if isYaml && reason == "" {
+				t.Fatal("expected a reason")
+			}
+		})
+	}
+}